@@ -17,8 +17,17 @@ func main() {
 
 	localizer := i18n.New(os.DirFS("locales"))
 
-	dbClient := db.NewClient(cfg)
+	var store db.Store
+	if cfg.LocalStorePath != "" {
+		localStore, err := db.NewLocalStore(cfg.LocalStorePath)
+		if err != nil {
+			log.Fatalf("Failed to open local store: %v", err)
+		}
+		store = localStore
+	} else {
+		store = db.NewClient(cfg)
+	}
 
-	b := bot.New(cfg, localizer, dbClient)
+	b := bot.New(cfg, localizer, store)
 	b.Start()
 }
\ No newline at end of file
@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"detektif-kata-bot/internal/db"
+)
+
+// achievementEvent menjelaskan kejadian yang memicu pemeriksaan lencana.
+// Field yang tidak berkaitan dengan kejadiannya dibiarkan kosong, jadi
+// kriteria yang membutuhkannya tidak terpenuhi.
+type achievementEvent struct {
+	// GuessSeconds adalah waktu tebakan benar di ronde grup, 0 jika bukan tebakan.
+	GuessSeconds float64
+	// Game berisi pencapaian pemain di satu permainan grup yang baru selesai.
+	Game *gameFeats
+	// SoloHints adalah jumlah petunjuk yang dipakai untuk menang di mode solo.
+	SoloHints int
+}
+
+// gameFeats adalah hasil seorang pemain di satu permainan grup.
+type gameFeats struct {
+	Points       int
+	WordsGuessed int
+	// GuessStreak adalah ronde berturut-turut terpanjang yang ditebak pemain,
+	// tanpa menghitung ronde saat ia sendiri memberi petunjuk.
+	GuessStreak int
+}
+
+// achievementCheck adalah data yang dibaca oleh setiap kriteria.
+type achievementCheck struct {
+	Player     *db.Player
+	BadgeCount int
+	Event      achievementEvent
+}
+
+// achievementRule memeriksa satu criteria_type. Kriteria kumulatif memakai
+// Progress, yang juga ditampilkan sebagai bilah kemajuan di profil; kriteria
+// yang hanya bisa terpenuhi pada satu kejadian memakai Met.
+type achievementRule struct {
+	Progress func(c *achievementCheck) int
+	Met      func(c *achievementCheck, value int) bool
+}
+
+func (r achievementRule) met(c *achievementCheck, value int) bool {
+	if r.Progress != nil {
+		return r.Progress(c) >= value
+	}
+	return r.Met(c, value)
+}
+
+func statRule(stat func(p *db.Player) int) achievementRule {
+	return achievementRule{Progress: func(c *achievementCheck) int { return stat(c.Player) }}
+}
+
+// achievementCriteria memetakan criteria_type di tabel badges ke aturannya.
+// Lencana dengan criteria_type yang tidak terdaftar tidak pernah diberikan.
+var achievementCriteria = map[string]achievementRule{
+	// Statistik pemain
+	"words_guessed_count": statRule(func(p *db.Player) int { return p.WordsGuessedCount }),
+	"games_played":        statRule(func(p *db.Player) int { return p.GamesPlayed }),
+	"games_won":           statRule(func(p *db.Player) int { return p.GamesWon }),
+	"clue_given_count":    statRule(func(p *db.Player) int { return p.ClueGivenCount }),
+	"clue_success_count":  statRule(func(p *db.Player) int { return p.ClueSuccessCount }),
+	"win_streak":          statRule(func(p *db.Player) int { return p.BestWinStreak }),
+	"points":              statRule(func(p *db.Player) int { return p.Points }),
+	"rating": statRule(func(p *db.Player) int {
+		if p.RatedGames == 0 {
+			return 0
+		}
+		return int(p.Rating)
+	}),
+	"badges_owned": {Progress: func(c *achievementCheck) int { return c.BadgeCount }},
+
+	// Kejadian tertentu
+	"guess_time": {Met: func(c *achievementCheck, v int) bool {
+		return c.Event.GuessSeconds > 0 && c.Event.GuessSeconds < float64(v)
+	}},
+	"game_points": {Met: func(c *achievementCheck, v int) bool {
+		return c.Event.Game != nil && c.Event.Game.Points >= v
+	}},
+	"game_words_guessed": {Met: func(c *achievementCheck, v int) bool {
+		return c.Event.Game != nil && c.Event.Game.WordsGuessed >= v
+	}},
+	"game_guess_streak": {Met: func(c *achievementCheck, v int) bool {
+		return c.Event.Game != nil && c.Event.Game.GuessStreak >= v
+	}},
+	"solo_hints": {Met: func(c *achievementCheck, v int) bool {
+		return c.Event.SoloHints > 0 && c.Event.SoloHints <= v
+	}},
+}
+
+// gameFeatsFor menghitung hasil seorang pemain dari riwayat permainan.
+func gameFeatsFor(record *db.GameDetail, playerID int64) *gameFeats {
+	feats := &gameFeats{}
+	for _, p := range record.Players {
+		if p.PlayerID == playerID {
+			feats.Points = p.Points
+		}
+	}
+	for _, g := range record.Guesses {
+		if g.PlayerID == playerID && g.Result == db.GuessCorrect {
+			feats.WordsGuessed++
+		}
+	}
+	streak := 0
+	for _, r := range record.Rounds {
+		// Ronde saat pemain memberi petunjuk tidak memutus rentetannya.
+		if r.ClueGiverID == playerID {
+			continue
+		}
+		if r.WinnerID != nil && *r.WinnerID == playerID {
+			streak++
+		} else {
+			streak = 0
+		}
+		if streak > feats.GuessStreak {
+			feats.GuessStreak = streak
+		}
+	}
+	return feats
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"html"
+	"log"
+	"sync"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// badgeCacheTTL adalah umur katalog lencana dan lencana pemain di memori.
+const badgeCacheTTL = 10 * time.Minute
+
+// badgeCache menyimpan katalog lencana dan emoji lencana yang ditampilkan di
+// depan nama setiap pemain, supaya papan skor tidak perlu query per pemain.
+type badgeCache struct {
+	mu       sync.Mutex
+	catalog  map[int]db.Badge
+	loadedAt time.Time
+	emojis   map[int64]string
+}
+
+func newBadgeCache() *badgeCache {
+	return &badgeCache{emojis: make(map[int64]string)}
+}
+
+// badgeCatalog mengembalikan semua lencana berdasarkan ID-nya, dan memuat
+// ulang katalog (serta mengosongkan cache emoji pemain) jika sudah kedaluwarsa.
+func (b *Bot) badgeCatalog() map[int]db.Badge {
+	c := b.badges
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.catalog != nil && time.Since(c.loadedAt) < badgeCacheTTL {
+		return c.catalog
+	}
+	badges, err := b.db.GetAllBadges()
+	if err != nil {
+		log.Printf("Failed to load badge catalog: %v", err)
+		if c.catalog == nil {
+			return map[int]db.Badge{}
+		}
+		return c.catalog
+	}
+	c.catalog = make(map[int]db.Badge, len(badges))
+	for _, badge := range badges {
+		c.catalog[badge.ID] = badge
+	}
+	c.loadedAt = time.Now()
+	c.emojis = make(map[int64]string)
+	return c.catalog
+}
+
+// invalidatePlayerBadges membuang emoji lencana pemain dari cache, misalnya
+// setelah ia memakai, membeli, atau mendapatkan lencana.
+func (b *Bot) invalidatePlayerBadges(playerID int64) {
+	b.badges.mu.Lock()
+	delete(b.badges.emojis, playerID)
+	b.badges.mu.Unlock()
+}
+
+// badgeEmojis mengembalikan emoji lencana untuk setiap pemain: lencana yang
+// dipakai, atau lencana dengan ID terkecil yang dimiliki jika belum memakai
+// lencana. Pemain yang belum ada di cache dimuat dengan dua query saja.
+func (b *Bot) badgeEmojis(playerIDs []int64) map[int64]string {
+	catalog := b.badgeCatalog()
+
+	result := make(map[int64]string, len(playerIDs))
+	var missing []int64
+	b.badges.mu.Lock()
+	for _, id := range playerIDs {
+		if emoji, ok := b.badges.emojis[id]; ok {
+			result[id] = emoji
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	b.badges.mu.Unlock()
+	if len(missing) == 0 {
+		return result
+	}
+
+	players, err := b.db.GetPlayersByIDs(missing)
+	if err != nil {
+		return result
+	}
+	var unequipped []int64
+	for _, p := range players {
+		if p.EquippedBadgeID != nil {
+			if badge, ok := catalog[*p.EquippedBadgeID]; ok {
+				result[p.TelegramUserID] = badge.Emoji
+				continue
+			}
+		}
+		unequipped = append(unequipped, p.TelegramUserID)
+	}
+
+	if len(unequipped) > 0 {
+		links, err := b.db.GetPlayerBadgeLinks(unequipped)
+		if err != nil {
+			return result
+		}
+		first := make(map[int64]int)
+		for _, link := range links {
+			if _, ok := catalog[link.BadgeID]; !ok {
+				continue
+			}
+			if current, ok := first[link.PlayerID]; !ok || link.BadgeID < current {
+				first[link.PlayerID] = link.BadgeID
+			}
+		}
+		for _, id := range unequipped {
+			if badgeID, ok := first[id]; ok {
+				result[id] = catalog[badgeID].Emoji
+			} else {
+				result[id] = ""
+			}
+		}
+	}
+
+	b.badges.mu.Lock()
+	for _, id := range missing {
+		if emoji, ok := result[id]; ok {
+			b.badges.emojis[id] = emoji
+		}
+	}
+	b.badges.mu.Unlock()
+	return result
+}
+
+// displayNames menyusun nama tampilan (lencana + nama) untuk banyak pemain sekaligus.
+func (b *Bot) displayNames(players []*db.Player) map[int64]string {
+	ids := make([]int64, len(players))
+	for i, p := range players {
+		ids[i] = p.TelegramUserID
+	}
+	emojis := b.badgeEmojis(ids)
+
+	names := make(map[int64]string, len(players))
+	for _, p := range players {
+		names[p.TelegramUserID] = withBadge(emojis[p.TelegramUserID], p.FirstName)
+	}
+	return names
+}
+
+// displayName menampilkan nama pemain diawali lencana yang dipakainya.
+func (b *Bot) displayName(p *db.Player) string {
+	return b.displayNames([]*db.Player{p})[p.TelegramUserID]
+}
+
+func withBadge(emoji, name string) string {
+	if emoji == "" {
+		return html.EscapeString(name)
+	}
+	return emoji + " " + html.EscapeString(name)
+}
+
+func playerIDs(players []db.Player) []int64 {
+	ids := make([]int64, len(players))
+	for i, p := range players {
+		ids[i] = p.TelegramUserID
+	}
+	return ids
+}
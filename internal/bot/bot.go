@@ -8,23 +8,43 @@ import (
 	"detektif-kata-bot/internal/db"
 	"detektif-kata-bot/internal/game"
 	"detektif-kata-bot/internal/i18n"
-	
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Bot struct {
-	api         *tgbotapi.BotAPI
-	cfg         *config.Config
-	localizer   *i18n.Localizer
-	db          *db.Client
-	gameStates  map[int64]*game.GameState
+	api            TelegramClient
+	cfg            *config.Config
+	localizer      *i18n.Localizer
+	db             db.Store
+	games          map[int64]*game.Engine
 	soloGameStates map[int64]*game.SoloGameState
-	botUsername string 
+	timers         map[int64]chatTimers
+	botUsername    string
 	mu             sync.RWMutex
+	timersMu       sync.Mutex
+	pmReachable    map[int64]bool
+	pmMu           sync.Mutex
+	cluePrompts    map[int64][]cluePrompt
+	pendingClues   map[pendingClueKey]string
+	routeMu        sync.Mutex
+	mailboxes      *mailboxSet
+	inbox          *mailboxSet
+	catalog        *game.Catalog
+	wordHistory    *game.WordHistory
+	badges         *badgeCache
+	ratingMu       sync.Mutex
+
+	// achievementChecks menjalankan pemeriksaan lencana satu per satu per pemain.
+	achievementChecks *mailboxSet
+
+	// backgroundJobs mencatat goroutine dari background supaya Stop bisa menunggunya.
+	backgroundJobs sync.WaitGroup
+	// stopped dijaga oleh timersMu; setelah Stop tidak ada timer baru yang dipasang.
+	stopped bool
 }
 
-func New(cfg *config.Config, localizer *i18n.Localizer, dbClient *db.Client) *Bot {
+func New(cfg *config.Config, localizer *i18n.Localizer, store db.Store) *Bot {
 	api, err := tgbotapi.NewBotAPI(cfg.TelegramBotToken)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
@@ -32,24 +52,112 @@ func New(cfg *config.Config, localizer *i18n.Localizer, dbClient *db.Client) *Bo
 
 	log.Printf("Authorized on account %s", api.Self.UserName)
 
+	return NewWithClient(api, api.Self.UserName, cfg, localizer, store)
+}
+
+// NewWithClient membuat Bot dengan klien Telegram yang sudah jadi, misalnya
+// klien palsu dari paket telegramfake, tanpa perlu koneksi ke Telegram.
+func NewWithClient(api TelegramClient, username string, cfg *config.Config, localizer *i18n.Localizer, store db.Store) *Bot {
 	return &Bot{
-		api:         api,
-		cfg:         cfg,
-		localizer:   localizer,
-		db:          dbClient,
-		gameStates:  make(map[int64]*game.GameState),
+		api:            api,
+		cfg:            cfg,
+		localizer:      localizer,
+		db:             store,
+		games:          make(map[int64]*game.Engine),
 		soloGameStates: make(map[int64]*game.SoloGameState),
-		botUsername: api.Self.UserName, // TANDA: Baris ini ditambahkan
+		timers:         make(map[int64]chatTimers),
+		catalog:        game.NewCatalog(store.GetWords, "id"),
+		wordHistory:    game.NewWordHistory(),
+		badges:         newBadgeCache(),
+		pmReachable:    make(map[int64]bool),
+		cluePrompts:    make(map[int64][]cluePrompt),
+		pendingClues:   make(map[pendingClueKey]string),
+		mailboxes:      newMailboxSet(),
+		inbox:          newMailboxSet(),
+		botUsername:    username,
+
+		achievementChecks: newMailboxSet(),
+	}
+}
+
+// newEngine membuat engine yang mengambil kata rahasia dari katalog sesuai
+// kategori permainan, tanpa mengulang kata yang baru saja dipakai di grup itu.
+func (b *Bot) newEngine(state *game.GameState) *game.Engine {
+	b.loadWordHistory(state.ChatID)
+
+	engine := game.NewEngine(state)
+	engine.PickWord = func(s *game.GameState) db.Word {
+		candidates := b.catalog.Words(s.Category)
+		if len(candidates) == 0 {
+			candidates = game.SeedWords()
+		}
+		if s.Mode == game.ModeTaboo {
+			if taboo := game.WithTaboo(candidates); len(taboo) > 0 {
+				candidates = taboo
+			}
+		}
+		word, _ := b.wordHistory.Choose(s.ChatID, candidates, s.Settings.NoRepeatWindow)
+		b.wordHistory.Record(s.ChatID, word.Word, engine.Now())
+		return word
 	}
+	return engine
+}
+
+// loadWordHistory memuat riwayat kata sebuah grup dari database sekali saja.
+func (b *Bot) loadWordHistory(chatID int64) {
+	if b.wordHistory.Loaded(chatID) {
+		return
+	}
+	usages, err := b.db.GetWordHistory(chatID, 500)
+	if err != nil {
+		return
+	}
+	b.wordHistory.Load(chatID, usages)
 }
 
 func (b *Bot) Start() {
+	b.restoreGames()
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
 	updates := b.api.GetUpdatesChan(u)
 
+	// Update dari chat yang sama ditangani berurutan sesuai urutan kedatangan;
+	// chat yang berbeda tetap berjalan bersamaan.
 	for update := range updates {
-		go b.handleUpdate(update)
+		update := update
+		b.inbox.enqueue(updateChatID(update), func() { b.handleUpdate(update) })
+	}
+}
+
+// Stop menghentikan semua timer lalu menunggu pekerjaan yang masih berjalan,
+// termasuk penulisan ke database di latar belakang, sampai selesai.
+func (b *Bot) Stop() {
+	b.timersMu.Lock()
+	b.stopped = true
+	for chatID, timers := range b.timers {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+		delete(b.timers, chatID)
 	}
-}
\ No newline at end of file
+	b.timersMu.Unlock()
+
+	b.inbox.wait()
+	b.mailboxes.wait()
+	b.backgroundJobs.Wait()
+	b.achievementChecks.wait()
+}
+
+// updateChatID mengembalikan chat asal update, atau 0 untuk update yang
+// tidak ditangani bot.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/bot"
+	"detektif-kata-bot/internal/config"
+	"detektif-kata-bot/internal/db"
+	"detektif-kata-bot/internal/i18n"
+	"detektif-kata-bot/internal/telegramfake"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const groupID = -100
+
+var (
+	group = tgbotapi.Chat{ID: groupID, Type: "group", Title: "Detektif"}
+	ani   = tgbotapi.User{ID: 1, FirstName: "Ani"}
+	budi  = tgbotapi.User{ID: 2, FirstName: "Budi"}
+	citra = tgbotapi.User{ID: 3, FirstName: "Citra"}
+)
+
+func private(u tgbotapi.User) tgbotapi.Chat {
+	return tgbotapi.Chat{ID: u.ID, Type: "private"}
+}
+
+// newTestBot membuat Bot dengan klien Telegram palsu dan LocalStore yang
+// katalognya hanya berisi satu kata, "kucing".
+func newTestBot(t *testing.T) (*bot.Bot, *telegramfake.Client, *i18n.Localizer) {
+	t.Helper()
+	raw, err := json.Marshal(map[string]interface{}{
+		"words": []db.Word{{Word: "kucing", Category: "hewan", Language: "id"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := db.NewLocalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := telegramfake.New()
+	localizer := i18n.New(os.DirFS("../../locales"))
+	b := bot.NewWithClient(client, "detektifbot", &config.Config{}, localizer, store)
+	// Stop dijalankan sebelum direktori sementara dihapus, jadi tidak ada
+	// goroutine bot yang masih menulis ke sana.
+	t.Cleanup(b.Stop)
+	return b, client, localizer
+}
+
+// phrase mengambil bagian tetap sebuah teks terjemahan, yaitu teks sebelum
+// placeholder pertama, untuk dicari di pesan yang terkirim.
+func phrase(l *i18n.Localizer, key string) string {
+	text := l.Get("id", key)
+	if i := strings.IndexByte(text, '{'); i > 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// waitFor menunggu sampai cond terpenuhi, atau menggagalkan tes setelah timeout.
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// countSent menghitung pesan ke sebuah chat yang mengandung teks tertentu.
+func countSent(client *telegramfake.Client, chatID int64, contains string) int {
+	n := 0
+	for _, m := range client.SentTo(chatID) {
+		if strings.Contains(m.Text, contains) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+	"detektif-kata-bot/internal/game"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// cluePrompt mengingat pesan kata rahasia yang dikirim ke Pemberi Petunjuk,
+// supaya balasan ke pesan itu bisa diarahkan ke grup yang benar.
+type cluePrompt struct {
+	ChatID    int64
+	MessageID int
+}
+
+// pendingClueKey menandai petunjuk yang menunggu pilihan grup, per pemain dan grup.
+type pendingClueKey struct {
+	UserID int64
+	ChatID int64
+}
+
+// rememberCluePrompt menyimpan pesan kata rahasia terbaru untuk sebuah grup.
+func (b *Bot) rememberCluePrompt(userID, chatID int64, messageID int) {
+	b.routeMu.Lock()
+	defer b.routeMu.Unlock()
+
+	prompts := b.cluePrompts[userID][:0]
+	for _, p := range b.cluePrompts[userID] {
+		if p.ChatID != chatID {
+			prompts = append(prompts, p)
+		}
+	}
+	b.cluePrompts[userID] = append(prompts, cluePrompt{ChatID: chatID, MessageID: messageID})
+}
+
+// clueTargets mengembalikan semua grup yang sedang menunggu petunjuk dari pemain ini.
+func (b *Bot) clueTargets(userID int64) []int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var targets []int64
+	for chatID, engine := range b.games {
+		state := engine.State
+		if state.IsActive && state.Status == game.StatusWaitingForClue && state.ClueGiver != nil && state.ClueGiver.TelegramUserID == userID {
+			targets = append(targets, chatID)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets
+}
+
+// routeClue menentukan grup tujuan sebuah petunjuk dari PM. Balasan ke pesan
+// kata rahasia selalu menuju grup pesan itu, dan ditolak jika grup itu tidak
+// lagi menunggu petunjuk. Jika pemain ditunggu di beberapa grup dan tidak
+// membalas, bot menanyakan grup tujuannya lebih dulu.
+// Mengembalikan false jika pemain tidak sedang ditunggu petunjuknya di mana pun.
+func (b *Bot) routeClue(message *tgbotapi.Message, player *db.Player, lang string) bool {
+	userID := player.TelegramUserID
+	targets := b.clueTargets(userID)
+	if len(targets) == 0 {
+		return false
+	}
+
+	if message.ReplyToMessage != nil {
+		b.routeMu.Lock()
+		var replyChatID int64
+		for _, p := range b.cluePrompts[userID] {
+			if p.MessageID == message.ReplyToMessage.MessageID {
+				replyChatID = p.ChatID
+			}
+		}
+		b.routeMu.Unlock()
+		if replyChatID != 0 {
+			for _, chatID := range targets {
+				if chatID == replyChatID {
+					b.handleClueSubmission(message, player, chatID, lang)
+					return true
+				}
+			}
+			// Balasan ke pesan kata rahasia grup lain tidak boleh dialihkan.
+			b.sendMessage(message.Chat.ID, b.localizer.Get(lang, "clue_prompt_stale"), true)
+			return true
+		}
+	}
+
+	if len(targets) == 1 {
+		b.handleClueSubmission(message, player, targets[0], lang)
+		return true
+	}
+
+	b.routeMu.Lock()
+	for _, chatID := range targets {
+		b.pendingClues[pendingClueKey{UserID: userID, ChatID: chatID}] = message.Text
+	}
+	b.routeMu.Unlock()
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, chatID := range targets {
+		title := b.gameTitle(chatID)
+		if title == "" {
+			title = strconv.FormatInt(chatID, 10)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(title, fmt.Sprintf("clue_for_%d", chatID)),
+		))
+	}
+	text := strings.Replace(b.localizer.Get(lang, "clue_choose_group"), "{clue}", html.EscapeString(message.Text), 1)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.api.Send(msg)
+	return true
+}
+
+// handleClueTargetCallback mengirim petunjuk yang tertunda ke grup yang dipilih.
+func (b *Bot) handleClueTargetCallback(query *tgbotapi.CallbackQuery, player *db.Player) {
+	lang := b.getUserLang(query.From)
+	chatID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "clue_for_"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	key := pendingClueKey{UserID: player.TelegramUserID, ChatID: chatID}
+	b.routeMu.Lock()
+	clue, ok := b.pendingClues[key]
+	delete(b.pendingClues, key)
+	b.routeMu.Unlock()
+	if !ok {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "clue_choose_group_expired"), true)
+		return
+	}
+
+	b.api.Request(tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+	b.answerCallback(query.ID, "", false)
+	b.handleClueSubmission(&tgbotapi.Message{Text: clue}, player, chatID, lang)
+}
+
+// gameTitle mengembalikan nama grup dari permainan yang sedang berjalan.
+func (b *Bot) gameTitle(chatID int64) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if engine, ok := b.games[chatID]; ok {
+		return engine.State.ChatTitle
+	}
+	return ""
+}
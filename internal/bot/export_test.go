@@ -0,0 +1,17 @@
+package bot
+
+import (
+	"detektif-kata-bot/internal/game"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Fungsi di berkas ini hanya ada saat tes, supaya tes di paket bot_test
+// bisa menjalankan update dan timer tanpa menunggu waktu sungguhan.
+
+func (b *Bot) HandleUpdate(update tgbotapi.Update) { b.handleUpdate(update) }
+
+func (b *Bot) Post(chatID int64, ev game.Event) { b.post(chatID, ev) }
+
+// Flush menunggu sampai semua pekerjaan yang sudah masuk ke mailbox chat selesai.
+func (b *Bot) Flush(chatID int64) { b.run(chatID, func() {}) }
@@ -4,57 +4,71 @@ import (
 	"fmt"
 	"html"
 	"log"
+
+	"detektif-kata-bot/internal/db"
 )
 
-// checkAndAwardAchievements memeriksa apakah pemain berhak mendapatkan lencana baru berdasarkan aksinya.
-func (b *Bot) checkAndAwardAchievements(playerID int64, chatID int64, playerName string, timeTaken float64) {
-	// 1. Ambil semua lencana tipe 'achievement' dari database
-	allAchievements, err := b.db.GetAchievementBadges()
-	if err != nil {
-		log.Printf("Failed to get all achievement badges: %v", err)
+// checkAchievements menjadwalkan pemeriksaan lencana pencapaian pemain
+// setelah sebuah kejadian, tanpa menunggu hasilnya. Pemeriksaan untuk pemain
+// yang sama berjalan berurutan di mailbox per pemain, jadi lencana yang sama
+// tidak diberikan atau diumumkan dua kali; pemain lain tetap diperiksa
+// bersamaan.
+func (b *Bot) checkAchievements(playerID int64, chatID int64, event achievementEvent) {
+	b.achievementChecks.enqueue(playerID, func() {
+		b.runAchievementCheck(playerID, chatID, event)
+	})
+}
+
+// runAchievementCheck memeriksa semua lencana pencapaian yang belum dimiliki
+// pemain, lalu memberikan yang syaratnya terpenuhi. Data pemain dibaca ulang
+// supaya statistik yang baru disimpan ikut dihitung.
+func (b *Bot) runAchievementCheck(playerID int64, chatID int64, event achievementEvent) {
+	player, err := b.db.GetPlayerByID(playerID)
+	if err != nil || player == nil {
+		log.Printf("Failed to get player %d for achievement check: %v", playerID, err)
 		return
 	}
 
-	// 2. Ambil semua lencana yang sudah dimiliki pemain
+	// Ambil semua lencana yang sudah dimiliki pemain
 	playerBadges, err := b.db.GetPlayerBadges(playerID)
 	if err != nil {
 		log.Printf("Failed to get player badges for achievement check: %v", err)
 		return
 	}
-
-	// Buat sebuah map untuk mengecek kepemilikan lencana dengan cepat
 	playerHasBadge := make(map[int]bool)
 	for _, badge := range playerBadges {
 		playerHasBadge[badge.ID] = true
 	}
 
-	// 3. Loop melalui semua lencana achievement dan periksa satu per satu
-	for _, achievement := range allAchievements {
-		// Lewati jika pemain sudah punya lencana ini
-		if playerHasBadge[achievement.ID] {
+	check := &achievementCheck{Player: player, BadgeCount: len(playerBadges), Event: event}
+	for _, achievement := range b.badgeCatalog() {
+		if achievement.Type != "achievement" || playerHasBadge[achievement.ID] {
 			continue
 		}
-
-		// Periksa kriteria untuk setiap lencana
-		switch achievement.CriteriaType {
-		case "guess_time":
-			if timeTaken < float64(achievement.CriteriaValue) {
-				// Pemain memenuhi syarat! Berikan lencana.
-				err := b.db.AwardBadgeToPlayer(playerID, achievement.ID)
-				if err == nil {
-					// Kirim pesan selamat ke grup
-					announcement := fmt.Sprintf(
-						"🎉 <b>PENCAPAIAN TERBUKA!</b> 🎉\n\n%s mendapatkan lencana <b>%s %s</b>: <i>%s</i>",
-						html.EscapeString(playerName),
-						achievement.Emoji,
-						achievement.Name,
-						achievement.Description,
-					)
-					b.sendMessage(chatID, announcement, true)
-				}
-			}
-		// case "total_guesses":
-		// Nanti kita bisa tambahkan logika untuk lencana lain di sini
+		rule, ok := achievementCriteria[achievement.CriteriaType]
+		if !ok || !rule.met(check, achievement.CriteriaValue) {
+			continue
+		}
+		if b.awardAchievement(player, chatID, achievement) {
+			check.BadgeCount++
 		}
 	}
-}
\ No newline at end of file
+}
+
+// awardAchievement memberikan lencana pencapaian lalu mengumumkannya di chat.
+func (b *Bot) awardAchievement(player *db.Player, chatID int64, achievement db.Badge) bool {
+	if err := b.db.AwardBadgeToPlayer(player.TelegramUserID, achievement.ID); err != nil {
+		return false
+	}
+	b.invalidatePlayerBadges(player.TelegramUserID)
+	// Kirim pesan selamat ke grup
+	announcement := fmt.Sprintf(
+		"🎉 <b>PENCAPAIAN TERBUKA!</b> 🎉\n\n%s mendapatkan lencana <b>%s %s</b>: <i>%s</i>",
+		html.EscapeString(player.FirstName),
+		achievement.Emoji,
+		achievement.Name,
+		achievement.Description,
+	)
+	b.sendMessage(chatID, announcement, true)
+	return true
+}
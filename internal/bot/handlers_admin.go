@@ -16,9 +16,11 @@ func (b *Bot) handleAdminCommand(message *tgbotapi.Message) {
 
 	switch message.Command() {
 	case "broadcast":
-		go b.handleBroadcast(message, "private")
+		b.background(func() { b.handleBroadcast(message, "private") })
 	case "broadcastgroup":
-		go b.handleBroadcast(message, "group")
+		b.background(func() { b.handleBroadcast(message, "group") })
+	case "ceksaldo":
+		b.handleLedgerAudit(message)
 	}
 }
 
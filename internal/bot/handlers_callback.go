@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"strconv"
@@ -33,12 +34,38 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 			b.answerCallback(query.ID, "Gagal memakai lencana.", true)
 			return
 		}
+		b.invalidatePlayerBadges(query.From.ID)
 		b.answerCallback(query.ID, "Lencana berhasil dipakai!", false)
 		// Setelah berhasil, segarkan tampilan profil
 		b.refreshProfileView(query, query.Message.MessageID)
 		return
 	}
 
+	if strings.HasPrefix(data, "clue_for_") {
+		b.handleClueTargetCallback(query, player)
+		return
+	}
+
+	if strings.HasPrefix(data, "reroll_") {
+		b.handleRerollCallback(query)
+		return
+	}
+
+	if strings.HasPrefix(data, "history_") {
+		b.handleHistoryCallback(query)
+		return
+	}
+
+	if strings.HasPrefix(data, "riwayat_") {
+		b.handleLedgerCallback(query)
+		return
+	}
+
+	if strings.HasPrefix(data, "settings_") {
+		b.handleSettingsCallback(query)
+		return
+	}
+
 	if strings.HasPrefix(query.Data, "shop_") {
 		b.handleShopCallback(query, player)
 		return
@@ -46,24 +73,35 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 
 
 	if strings.HasPrefix(query.Data, "join_game") {
-		b.mu.Lock()
-		defer b.mu.Unlock()
-
-		state, ok := b.gameStates[chatID]
-		if !ok || !state.IsActive || state.Status != game.StatusLobby {
-			b.answerCallback(query.ID, "Lobi sudah ditutup.", true)
-			return
-		}
-
-		if _, joined := state.Players[player.TelegramUserID]; joined {
+		err := b.dispatch(chatID, game.JoinEvent{Player: player})
+		switch {
+		case errors.Is(err, game.ErrAlreadyJoined):
 			b.answerCallback(query.ID, b.localizer.Get(lang, "callback_already_joined"), true)
-			return
+		case errors.Is(err, game.ErrGameFull):
+			b.answerCallback(query.ID, b.localizer.Get(lang, "lobby_full"), true)
+		case err != nil:
+			b.answerCallback(query.ID, b.localizer.Get(lang, "lobby_closed"), true)
+		case !b.canReachPrivately(player):
+			// Buka chat pribadi langsung lewat deep link supaya pemain bisa menerima kata rahasia.
+			callback := tgbotapi.NewCallback(query.ID, "")
+			callback.URL = b.startPMURL(chatID)
+			b.api.Request(callback)
+		default:
+			b.answerCallback(query.ID, b.localizer.Get(lang, "callback_join_success"), false)
 		}
+		return
+	}
 
-		state.Players[player.TelegramUserID] = player
-		b.answerCallback(query.ID, b.localizer.Get(lang, "callback_join_success"), false)
-
-		go b.updateLobbyMessage(chatID)
+	if data == "leave_game" {
+		err := b.dispatch(chatID, game.LeaveEvent{PlayerID: player.TelegramUserID})
+		switch {
+		case errors.Is(err, game.ErrNotParticipant):
+			b.answerCallback(query.ID, b.localizer.Get(lang, "leave_not_participant"), true)
+		case err != nil:
+			b.answerCallback(query.ID, b.localizer.Get(lang, "lobby_closed"), true)
+		default:
+			b.answerCallback(query.ID, b.localizer.Get(lang, "callback_leave_success"), false)
+		}
 		return
 	}
 	
@@ -107,26 +145,41 @@ func (b *Bot) createHelpBackButton(lang string) tgbotapi.InlineKeyboardMarkup {
 }
 
 func (b *Bot) updateLobbyMessage(chatID int64) (tgbotapi.Message, error) {
-	b.mu.RLock()
-	state := b.gameStates[chatID]
-	b.mu.RUnlock()
-	
 	lang := "id"
 
+	b.mu.RLock()
+	engine, ok := b.games[chatID]
+	if !ok {
+		b.mu.RUnlock()
+		return tgbotapi.Message{}, game.ErrGameNotActive
+	}
+	state := engine.State
+	lobbyMessageID := state.LobbyMessageID
+
 	var playerList strings.Builder
+	needsPM := false
 	if len(state.Players) == 0 {
 		playerList.WriteString(b.localizer.Get(lang, "lobby_no_players"))
 	} else {
 		i := 1
 		for _, p := range state.Players {
-			playerList.WriteString(fmt.Sprintf("%d. %s\n", i, html.EscapeString(p.FirstName)))
+			mark := ""
+			if !b.canReachPrivately(p) {
+				mark = " ⚠️"
+				needsPM = true
+			}
+			playerList.WriteString(fmt.Sprintf("%d. %s%s\n", i, html.EscapeString(p.FirstName), mark))
 			i++
 		}
 	}
 
 	playersJoinedText := b.localizer.Get(lang, "lobby_players_joined")
 	playersJoinedText = strings.Replace(playersJoinedText, "{player_count}", strconv.Itoa(len(state.Players)), 1)
-	playersJoinedText = strings.Replace(playersJoinedText, "{max_players}", strconv.Itoa(state.TotalRounds), 1)
+	maxPlayers := "∞"
+	if state.Settings.MaxPlayers > 0 {
+		maxPlayers = strconv.Itoa(state.Settings.MaxPlayers)
+	}
+	playersJoinedText = strings.Replace(playersJoinedText, "{max_players}", maxPlayers, 1)
 	playersJoinedText = strings.Replace(playersJoinedText, "{player_list}", playerList.String(), 1)
 
 	hostText := b.localizer.Get(lang, "lobby_host")
@@ -134,9 +187,37 @@ func (b *Bot) updateLobbyMessage(chatID int64) (tgbotapi.Message, error) {
 
 	joinPromptText := b.localizer.Get(lang, "lobby_join_prompt")
 	joinPromptText = strings.Replace(joinPromptText, "{total_rounds}", strconv.Itoa(state.TotalRounds), 1)
+	if state.Category != "" {
+		categoryText := b.localizer.Get(lang, "lobby_category")
+		categoryText = strings.Replace(categoryText, "{category}", html.EscapeString(state.Category), 1)
+		joinPromptText += "\n" + categoryText
+	}
+	if state.Mode == game.ModeTaboo {
+		joinPromptText += "\n" + b.localizer.Get(lang, "lobby_mode_taboo")
+	}
 
-	playInstructionText := b.localizer.Get(lang, "lobby_play_instruction")
-	playInstructionText = strings.Replace(playInstructionText, "{host_name}", html.EscapeString(state.Host.FirstName), 1)
+	// Baris terakhir menunjukkan keadaan lobi: menunggu host, hitung mundur, penuh, atau sudah ditutup.
+	open := state.Status == game.StatusLobby
+	full := state.Settings.MaxPlayers > 0 && len(state.Players) >= state.Settings.MaxPlayers
+	var playInstructionText string
+	if !open {
+		playInstructionText = b.localizer.Get(lang, "lobby_closed")
+	} else {
+		playInstructionText = b.localizer.Get(lang, "lobby_play_instruction")
+		playInstructionText = strings.Replace(playInstructionText, "{host_name}", html.EscapeString(state.Host.FirstName), 1)
+		if left, ok := engine.AutoStartIn(); ok {
+			countdown := b.localizer.Get(lang, "lobby_auto_start_countdown")
+			countdown = strings.Replace(countdown, "{seconds}", strconv.Itoa(int(left.Seconds())), 1)
+			playInstructionText += "\n" + countdown
+		}
+		if full {
+			playInstructionText += "\n" + b.localizer.Get(lang, "lobby_full")
+		}
+		if needsPM {
+			playInstructionText += "\n" + b.localizer.Get(lang, "lobby_pm_needed")
+		}
+	}
+	b.mu.RUnlock()
 
 	fullText := fmt.Sprintf("%s\n%s\n\n%s\n\n%s\n%s",
 		b.localizer.Get(lang, "lobby_opened"),
@@ -146,16 +227,30 @@ func (b *Bot) updateLobbyMessage(chatID int64) (tgbotapi.Message, error) {
 		playInstructionText,
 	)
 
-	button := tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_join_game"), "join_game")
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button))
+	var buttons []tgbotapi.InlineKeyboardButton
+	if open && !full {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_join_game"), "join_game"))
+	}
+	if open {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_leave_game"), "leave_game"))
+	}
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if len(buttons) > 0 {
+		keyboard = tgbotapi.NewInlineKeyboardMarkup(buttons)
+	}
+	if open && needsPM {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(b.localizer.Get(lang, "button_start_pm"), b.startPMURL(chatID)),
+		))
+	}
 
-	if state.LobbyMessageID == 0 {
+	if lobbyMessageID == 0 {
 		msg := tgbotapi.NewMessage(chatID, fullText)
 		msg.ParseMode = tgbotapi.ModeHTML
 		msg.ReplyMarkup = keyboard
 		return b.api.Send(msg)
 	} else {
-		msg := tgbotapi.NewEditMessageText(chatID, state.LobbyMessageID, fullText)
+		msg := tgbotapi.NewEditMessageText(chatID, lobbyMessageID, fullText)
 		msg.ParseMode = tgbotapi.ModeHTML
 		msg.ReplyMarkup = &keyboard
 		_, err := b.api.Request(msg)
@@ -241,20 +336,20 @@ func (b *Bot) handleShopCallback(query *tgbotapi.CallbackQuery, player *db.Playe
 			return
 		}
 
-		updatedPlayer, _ := b.db.GetOrCreatePlayer(&config.User{ID: player.TelegramUserID})
-		if updatedPlayer.Points < badgeToBuy.CriteriaValue {
+		// Saldo diperiksa dan dikurangi sekaligus di database.
+		_, err = b.db.SpendPoints(player.TelegramUserID, badgeToBuy.CriteriaValue, db.LedgerPurchase, strconv.Itoa(badgeID))
+		if errors.Is(err, db.ErrInsufficientPoints) {
 			b.answerCallback(query.ID, b.localizer.Get(lang, "shop_not_enough_points"), true)
 			return
 		}
-
-		err = b.db.AddPoints(player.TelegramUserID, -badgeToBuy.CriteriaValue)
 		if err != nil {
 			b.answerCallback(query.ID, b.localizer.Get(lang, "shop_purchase_fail_process"), true)
 			return
 		}
 		err = b.db.AwardBadgeToPlayer(player.TelegramUserID, badgeID)
+		b.invalidatePlayerBadges(player.TelegramUserID)
 		if err != nil {
-			b.db.AddPoints(player.TelegramUserID, badgeToBuy.CriteriaValue)
+			b.db.AddPoints(player.TelegramUserID, badgeToBuy.CriteriaValue, db.LedgerRefund, strconv.Itoa(badgeID))
 			b.answerCallback(query.ID, b.localizer.Get(lang, "shop_purchase_fail_award"), true)
 			return
 		}
@@ -264,6 +359,7 @@ func (b *Bot) handleShopCallback(query *tgbotapi.CallbackQuery, player *db.Playe
 		editMsg.ParseMode = tgbotapi.ModeHTML
 		b.api.Request(editMsg)
 		b.answerCallback(query.ID, b.localizer.Get(lang, "shop_purchase_success"), false)
+		b.checkAchievements(player.TelegramUserID, chatID, achievementEvent{})
 	}
 
 	// Kasus: Kembali ke menu utama toko
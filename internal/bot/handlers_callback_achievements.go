@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"html"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// progressBarWidth adalah jumlah kotak pada bilah kemajuan pencapaian.
+const progressBarWidth = 10
+
+// displayAchievementsView menampilkan semua lencana pencapaian beserta
+// kemajuan pemain menuju setiap lencana. Tampilan ini hanya membaca data;
+// lencana diberikan oleh pemeriksaan setelah kejadian permainan.
+func (b *Bot) displayAchievementsView(query *tgbotapi.CallbackQuery, messageID int) {
+	chatID := query.Message.Chat.ID
+	userID := query.From.ID
+	lang := b.getUserLang(query.From)
+
+	text, err := b.achievementsText(userID, lang)
+	if err != nil {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "profile_load_error"), true)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Kembali ke Profil", "profile_action_refresh"),
+		),
+	)
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	editMsg.ParseMode = tgbotapi.ModeHTML
+	editMsg.ReplyMarkup = &keyboard
+	b.api.Request(editMsg)
+	b.answerCallback(query.ID, "", false)
+}
+
+// achievementsText menyusun daftar pencapaian: yang sudah terbuka beserta
+// tanggalnya lebih dulu, lalu yang masih terkunci dengan bilah kemajuannya.
+func (b *Bot) achievementsText(playerID int64, lang string) (string, error) {
+	player, err := b.db.GetPlayerByID(playerID)
+	if err != nil || player == nil {
+		log.Printf("Failed to get player %d for achievements view: %v", playerID, err)
+		return "", err
+	}
+	achievements, err := b.db.GetAchievementBadges()
+	if err != nil {
+		log.Printf("Failed to get achievement badges: %v", err)
+		return "", err
+	}
+	links, err := b.db.GetPlayerBadgeLinks([]int64{playerID})
+	if err != nil {
+		return "", err
+	}
+	owned := make(map[int]db.PlayerBadge, len(links))
+	for _, link := range links {
+		owned[link.BadgeID] = link
+	}
+
+	if len(achievements) == 0 {
+		return b.localizer.Get(lang, "achievements_empty"), nil
+	}
+	sort.SliceStable(achievements, func(i, j int) bool {
+		_, iOwned := owned[achievements[i].ID]
+		_, jOwned := owned[achievements[j].ID]
+		if iOwned != jOwned {
+			return iOwned
+		}
+		return achievements[i].ID < achievements[j].ID
+	})
+
+	check := &achievementCheck{Player: player, BadgeCount: len(links)}
+	unlocked := 0
+	var entries strings.Builder
+	for _, achievement := range achievements {
+		var entry string
+		if link, ok := owned[achievement.ID]; ok {
+			unlocked++
+			if link.AwardedAt != nil {
+				entry = b.localizer.Get(lang, "achievement_unlocked")
+				entry = strings.Replace(entry, "{date}", link.AwardedAt.In(time.Local).Format("02/01/2006"), 1)
+			} else {
+				entry = b.localizer.Get(lang, "achievement_unlocked_undated")
+			}
+		} else if rule, ok := achievementCriteria[achievement.CriteriaType]; ok && rule.Progress != nil {
+			current := rule.Progress(check)
+			entry = b.localizer.Get(lang, "achievement_locked_progress")
+			entry = strings.Replace(entry, "{bar}", progressBar(current, achievement.CriteriaValue), 1)
+			entry = strings.Replace(entry, "{current}", strconv.Itoa(min(current, achievement.CriteriaValue)), 1)
+			entry = strings.Replace(entry, "{target}", strconv.Itoa(achievement.CriteriaValue), 1)
+		} else {
+			entry = b.localizer.Get(lang, "achievement_locked_feat")
+		}
+		entry = strings.Replace(entry, "{emoji}", achievement.Emoji, 1)
+		entry = strings.Replace(entry, "{name}", html.EscapeString(achievement.Name), 1)
+		entry = strings.Replace(entry, "{description}", html.EscapeString(achievement.Description), 1)
+		entries.WriteString(entry)
+	}
+
+	title := b.localizer.Get(lang, "achievements_title")
+	title = strings.Replace(title, "{unlocked}", strconv.Itoa(unlocked), 1)
+	title = strings.Replace(title, "{total}", strconv.Itoa(len(achievements)), 1)
+	return title + entries.String(), nil
+}
+
+// progressBar menggambar kemajuan current dari target, misalnya "▰▰▰▱▱▱▱▱▱▱".
+func progressBar(current, target int) string {
+	filled := progressBarWidth
+	if target > 0 && current < target {
+		filled = current * progressBarWidth / target
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("▰", filled) + strings.Repeat("▱", progressBarWidth-filled)
+}
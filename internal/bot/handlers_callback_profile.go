@@ -27,6 +27,8 @@ func (b *Bot) handleProfileActionCallback(query *tgbotapi.CallbackQuery) {
 	case "equip":
 		// TANDA: Sekarang kita kirim messageID ke fungsi equip
 		b.displayEquipBadgeView(query, messageID)
+	case "achievements":
+		b.displayAchievementsView(query, messageID)
 	}
 }
 
@@ -45,15 +47,14 @@ func (b *Bot) refreshProfileView(query *tgbotapi.CallbackQuery, messageID int) {
 	// Logika pembuatan teks profil disalin dari handleProfileCommand
 	var mainBadgeDisplay string
 	if player.EquippedBadgeID != nil {
-		badge, err := b.db.GetBadgeByID(*player.EquippedBadgeID)
-		if err == nil {
+		if badge, ok := b.badgeCatalog()[*player.EquippedBadgeID]; ok {
 			mainBadgeDisplay = badge.Emoji + " "
 		}
 	}
 
 	winRate := 0.0
-	if player.GamesPlayed > 0 {
-		winRate = (float64(player.GamesWon) / float64(player.GamesPlayed)) * 100
+	if player.GamesCounted > 0 {
+		winRate = (float64(player.GamesWon) / float64(player.GamesCounted)) * 100
 	}
 
 	clueSuccessRate := 0.0
@@ -81,23 +82,27 @@ func (b *Bot) refreshProfileView(query *tgbotapi.CallbackQuery, messageID int) {
 	profileText := fmt.Sprintf(
 		"--- 👤 PROFIL PEMAIN ---\n"+
 		"<b>Nama:</b> %s%s\n"+
-		"<b>Poin:</b> %d\n\n"+
+		"<b>Poin:</b> %d\n"+
+		"%s\n"+
 		"--- 📊 STATISTIK ---\n"+
 		"• Main: %d | Menang: %d (%.0f%% Win Rate)\n"+
 		"• Total Tebakan: %d kata\n"+
 		"• Tebakan Tercepat: %s\n"+
 		"• Sukses Beri Petunjuk: %.0f%%\n\n"+
+		"%s"+
 		"--- 🎖️ KOLEKSI LENCANA ---\n"+
 		"%s",
 		mainBadgeDisplay,
 		html.EscapeString(player.FirstName),
 		player.Points,
+		b.profileRating(player, lang),
 		player.GamesPlayed,
 		player.GamesWon,
 		winRate,
 		player.WordsGuessedCount,
 		fastestGuessDisplay,
 		clueSuccessRate,
+		b.historyProfileStats(player.TelegramUserID, lang),
 		allBadgesDisplay,
 	)
 
@@ -107,6 +112,7 @@ func (b *Bot) refreshProfileView(query *tgbotapi.CallbackQuery, messageID int) {
 			tgbotapi.NewInlineKeyboardButtonData("🏆 Papan Peringkat", "profile_action_leaderboard"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎯 Pencapaian", "profile_action_achievements"),
 			tgbotapi.NewInlineKeyboardButtonData("🔄 Segarkan", "profile_action_refresh"),
 		),
 	)
@@ -131,6 +137,7 @@ func (b *Bot) displayLeaderboardView(query *tgbotapi.CallbackQuery, messageID in
 	var leaderboardText strings.Builder
 	leaderboardText.WriteString(b.localizer.Get(lang, "leaderboard_title"))
 	rankEmojis := []string{"🥇", "🥈", "🥉"}
+	emojis := b.badgeEmojis(playerIDs(players))
 
 	for i, p := range players {
 		rank := fmt.Sprintf("%d.", i+1)
@@ -138,22 +145,7 @@ func (b *Bot) displayLeaderboardView(query *tgbotapi.CallbackQuery, messageID in
 			rank = rankEmojis[i]
 		}
 
-		badgeDisplay := ""
-		// Prioritaskan lencana yang dipakai
-		if p.EquippedBadgeID != nil {
-			badge, err := b.db.GetBadgeByID(*p.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			// Fallback ke lencana pertama jika tidak ada yang dipakai
-			playerBadges, _ := b.db.GetPlayerBadges(p.TelegramUserID)
-			if len(playerBadges) > 0 {
-				badgeDisplay = playerBadges[0].Emoji + " "
-			}
-		}
-
-		playerNameDisplay := badgeDisplay + html.EscapeString(p.FirstName)
+		playerNameDisplay := withBadge(emojis[p.TelegramUserID], p.FirstName)
 		entry := b.localizer.Get(lang, "leaderboard_entry")
 		entry = strings.Replace(entry, "{rank_emoji}", rank, 1)
 		entry = strings.Replace(entry, "{name}", playerNameDisplay, 1)
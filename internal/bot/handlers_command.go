@@ -1,11 +1,13 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"detektif-kata-bot/internal/db"
 	"detektif-kata-bot/internal/game"
@@ -25,6 +27,10 @@ func (b *Bot) handleCommand(message *tgbotapi.Message, player *db.Player) {
 		b.handlePlayCommand(message, player)
 	case "end":
 		b.handleEndCommand(message, player)
+	case "join":
+		b.handleJoinCommand(message, player)
+	case "leave":
+		b.handleLeaveCommand(message, player)
 	case "startalone":
 		b.handleStartAloneCommand(message, player)
 	case "leaderboard", "topglobal":
@@ -33,7 +39,13 @@ func (b *Bot) handleCommand(message *tgbotapi.Message, player *db.Player) {
 		b.handleProfileCommand(message)
 	case "toko", "market":
 		b.handleTokoCommand(message, player)
-	case "broadcast", "broadcastgroup": 
+	case "settings":
+		b.handleSettingsCommand(message)
+	case "riwayat":
+		b.handleRiwayatCommand(message, player)
+	case "history":
+		b.handleHistoryCommand(message)
+	case "broadcast", "broadcastgroup", "ceksaldo": 
 		b.handleAdminCommand(message)
 	default:
 	}
@@ -76,6 +88,11 @@ func (b *Bot) handleStartCommand(message *tgbotapi.Message) {
 		msg.ReplyMarkup = keyboard
 		b.api.Send(msg)
 	}
+
+	if payload := message.CommandArguments(); message.Chat.IsPrivate() && strings.HasPrefix(payload, startPayloadJoin) {
+		b.sendMessage(chatID, b.localizer.Get(lang, "pm_connected"), true)
+		b.handleStartPayload(payload)
+	}
 }
 
 func (b *Bot) handleStartGameCommand(message *tgbotapi.Message, player *db.Player) {
@@ -87,92 +104,164 @@ func (b *Bot) handleStartGameCommand(message *tgbotapi.Message, player *db.Playe
 	}
 	
 	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
+	_, ok := b.games[chatID]
 	b.mu.RUnlock()
-	if ok && state.IsActive {
+	if ok {
 		b.sendMessage(chatID, b.localizer.Get(lang, "game_already_running"), false)
 		return
 	}
 
-	args := message.CommandArguments()
-	totalRounds := 10 // Default
-	minRounds := 3
-	maxRounds := 25
-
-	if args != "" {
-		parsedRounds, err := strconv.Atoi(args)
-		if err == nil && parsedRounds >= minRounds && parsedRounds <= maxRounds {
+	settings, _ := b.db.GetChatSettings(chatID)
+
+	totalRounds := settings.DefaultRounds
+	category := ""
+	mode := game.ModeClassic
+
+	// Argumen bisa berupa jumlah ronde, kategori dan mode, dalam urutan apa pun: /startgame 10 hewan tabu
+	for _, arg := range strings.Fields(message.CommandArguments()) {
+		parsedRounds, err := strconv.Atoi(arg)
+		if err != nil {
+			switch strings.ToLower(arg) {
+			case "tabu", "taboo":
+				mode = game.ModeTaboo
+			default:
+				category = strings.ToLower(arg)
+			}
+			continue
+		}
+		if parsedRounds >= settings.MinRounds && parsedRounds <= settings.MaxRounds {
 			totalRounds = parsedRounds
 		} else {
 			// Kirim pesan jika input tidak valid, tapi tetap mulai game dengan default
-			invalidRoundsMsg := fmt.Sprintf("Jumlah ronde tidak valid. Harus antara %d dan %d. Memulai dengan %d ronde.", minRounds, maxRounds, totalRounds)
+			invalidRoundsMsg := b.localizer.Get(lang, "invalid_rounds_input")
+			invalidRoundsMsg = strings.Replace(invalidRoundsMsg, "{min_rounds}", strconv.Itoa(settings.MinRounds), 1)
+			invalidRoundsMsg = strings.Replace(invalidRoundsMsg, "{max_rounds}", strconv.Itoa(settings.MaxRounds), 1)
+			invalidRoundsMsg = strings.Replace(invalidRoundsMsg, "{total_rounds}", strconv.Itoa(totalRounds), 1)
 			b.sendMessage(chatID, invalidRoundsMsg, false)
 		}
 	}
-	// TANDA: Logika untuk menentukan jumlah ronde berakhir di sini
 
-	b.mu.Lock()
-	b.gameStates[chatID] = game.NewGame(chatID, player, totalRounds) // TANDA: totalRounds dimasukkan saat membuat game baru
-	b.gameStates[chatID].Players[player.TelegramUserID] = player
-	b.mu.Unlock()
+	if category != "" && !b.catalog.HasCategory(category) {
+		text := b.localizer.Get(lang, "category_not_found")
+		text = strings.Replace(text, "{category}", html.EscapeString(category), 1)
+		text = strings.Replace(text, "{categories}", strings.Join(b.catalog.Categories(), ", "), 1)
+		b.sendMessage(chatID, text, true)
+		return
+	}
 
-	lobbyMsg, err := b.updateLobbyMessage(chatID)
-	if err != nil {
-		delete(b.gameStates, chatID)
+	state := game.NewGame(chatID, player, totalRounds, game.SettingsFromChat(settings))
+	state.ChatTitle = message.Chat.Title
+	state.Category = category
+	state.Mode = mode
+	state.Players[player.TelegramUserID] = player
+
+	engine := b.newEngine(state)
+
+	// Lobi dibuat di dalam mailbox chat, jadi tombol ikut main yang ditekan
+	// sebelum pesan lobi terkirim baru diproses setelahnya.
+	created := false
+	b.run(chatID, func() {
+		b.mu.Lock()
+		if _, exists := b.games[chatID]; exists {
+			b.mu.Unlock()
+			return
+		}
+		b.games[chatID] = engine
+		b.mu.Unlock()
+
+		lobbyMsg, err := b.updateLobbyMessage(chatID)
+		if err != nil {
+			b.removeGame(chatID)
+			return
+		}
+		b.mu.Lock()
+		state.LobbyMessageID = lobbyMsg.MessageID
+		b.mu.Unlock()
+		b.saveGame(chatID)
+		created = true
+	})
+	if !created {
 		return
 	}
-	b.gameStates[chatID].LobbyMessageID = lobbyMsg.MessageID
+	b.dispatch(chatID, game.OpenLobbyEvent{})
 }
 
 func (b *Bot) handlePlayCommand(message *tgbotapi.Message, player *db.Player) {
 	chatID := message.Chat.ID
 	lang := b.getUserLang(message.From)
-	
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
 
-	if !ok || !state.IsActive || state.Status != game.StatusLobby {
-		return
-	}
-
-	if player.TelegramUserID != state.Host.TelegramUserID {
+	err := b.dispatch(chatID, game.StartEvent{PlayerID: player.TelegramUserID})
+	switch {
+	case errors.Is(err, game.ErrNotHost):
 		text := b.localizer.Get(lang, "play_command_not_host")
-		text = strings.Replace(text, "{host_name}", html.EscapeString(state.Host.FirstName), 1)
+		text = strings.Replace(text, "{host_name}", html.EscapeString(b.gameHost(chatID).FirstName), 1)
 		b.sendMessage(chatID, text, true)
-		return
-	}
-
-	if len(state.Players) < 2 {
+	case errors.Is(err, game.ErrNotEnoughPlayers):
 		b.sendMessage(chatID, b.localizer.Get(lang, "play_command_not_enough_players"), false)
-		return
 	}
+}
 
-	b.startGame(chatID)
+// handleJoinCommand memasukkan pemain ke lobi atau ke permainan yang sudah berjalan.
+func (b *Bot) handleJoinCommand(message *tgbotapi.Message, player *db.Player) {
+	chatID := message.Chat.ID
+	lang := b.getUserLang(message.From)
+
+	err := b.dispatch(chatID, game.JoinEvent{Player: player})
+	switch {
+	case errors.Is(err, game.ErrAlreadyJoined):
+		b.sendMessage(chatID, b.localizer.Get(lang, "callback_already_joined"), false)
+	case errors.Is(err, game.ErrGameFull):
+		b.sendMessage(chatID, b.localizer.Get(lang, "lobby_full"), false)
+	case err != nil:
+		b.sendMessage(chatID, b.localizer.Get(lang, "game_not_found"), false)
+	case !b.canReachPrivately(player):
+		text := strings.Replace(b.localizer.Get(lang, "join_needs_pm"), "{name}", html.EscapeString(player.FirstName), 1)
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(b.localizer.Get(lang, "button_start_pm"), b.startPMURL(chatID)),
+		))
+		b.api.Send(msg)
+	}
 }
 
-func (b *Bot) handleEndCommand(message *tgbotapi.Message, player *db.Player) {
+// handleLeaveCommand mengeluarkan pemain dari lobi atau permainan yang sedang berjalan.
+func (b *Bot) handleLeaveCommand(message *tgbotapi.Message, player *db.Player) {
 	chatID := message.Chat.ID
 	lang := b.getUserLang(message.From)
-	
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
 
-	if !ok || !state.IsActive {
+	err := b.dispatch(chatID, game.LeaveEvent{PlayerID: player.TelegramUserID})
+	switch {
+	case errors.Is(err, game.ErrNotParticipant):
+		b.sendMessage(chatID, b.localizer.Get(lang, "leave_not_participant"), false)
+	case err != nil:
 		b.sendMessage(chatID, b.localizer.Get(lang, "game_not_found"), false)
-		return
 	}
+}
 
-	if player.TelegramUserID != state.Host.TelegramUserID {
+func (b *Bot) handleEndCommand(message *tgbotapi.Message, player *db.Player) {
+	chatID := message.Chat.ID
+	lang := b.getUserLang(message.From)
+
+	err := b.dispatch(chatID, game.EndEvent{PlayerID: player.TelegramUserID})
+	switch {
+	case errors.Is(err, game.ErrGameNotActive):
+		b.sendMessage(chatID, b.localizer.Get(lang, "game_not_found"), false)
+	case errors.Is(err, game.ErrNotHost):
 		text := b.localizer.Get(lang, "end_command_not_host")
-		text = strings.Replace(text, "{host_name}", html.EscapeString(state.Host.FirstName), 1)
+		text = strings.Replace(text, "{host_name}", html.EscapeString(b.gameHost(chatID).FirstName), 1)
 		b.sendMessage(chatID, text, true)
-		return
 	}
+}
 
-	reason := b.localizer.Get(lang, "game_ended_by_host")
-	b.endGame(chatID, reason)
+// gameHost mengembalikan host permainan yang sedang berjalan di sebuah chat.
+func (b *Bot) gameHost(chatID int64) *db.Player {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if engine, ok := b.games[chatID]; ok {
+		return engine.State.Host
+	}
+	return &db.Player{}
 }
 
 func (b *Bot) handleStartAloneCommand(message *tgbotapi.Message, player *db.Player) {
@@ -183,21 +272,42 @@ func (b *Bot) handleStartAloneCommand(message *tgbotapi.Message, player *db.Play
 		return
 	}
 
-	b.mu.RLock()
-	state, ok := b.soloGameStates[player.TelegramUserID]
-	b.mu.RUnlock()
-	if ok && state.IsActive {
-		b.sendMessage(chatID, b.localizer.Get(lang, "solo_game_already_running"), false)
-		return
-	}
-	b.startSoloGame(chatID, player, lang)
+	b.run(player.TelegramUserID, func() {
+		b.mu.RLock()
+		state, ok := b.soloGameStates[player.TelegramUserID]
+		b.mu.RUnlock()
+		if ok && state.IsActive {
+			b.sendMessage(chatID, b.localizer.Get(lang, "solo_game_already_running"), false)
+			return
+		}
+		b.startSoloGame(chatID, player, lang)
+	})
 }
 
 func (b *Bot) handleLeaderboardCommand(message *tgbotapi.Message) {
 	chatID := message.Chat.ID
 	lang := b.getUserLang(message.From)
 
-	players, err := b.db.GetTopPlayers(10)
+	// "/leaderboard wins" mengurutkan berdasarkan jumlah kemenangan dan
+	// "/leaderboard rating" berdasarkan rating kemampuan.
+	mode := "points"
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "wins", "menang":
+		mode = "wins"
+	case "rating":
+		mode = "rating"
+	}
+
+	var players []db.Player
+	var err error
+	switch mode {
+	case "wins":
+		players, err = b.db.GetTopPlayersByWins(10)
+	case "rating":
+		players, err = b.db.GetTopPlayersByRating(10)
+	default:
+		players, err = b.db.GetTopPlayers(10)
+	}
 	if err != nil {
 		log.Printf("Failed to get top players: %v", err)
 		return
@@ -209,9 +319,18 @@ func (b *Bot) handleLeaderboardCommand(message *tgbotapi.Message) {
 	}
 
 	var leaderboardText strings.Builder
-	leaderboardText.WriteString(b.localizer.Get(lang, "leaderboard_title"))
+	switch mode {
+	case "wins":
+		leaderboardText.WriteString(b.localizer.Get(lang, "leaderboard_wins_title"))
+	case "rating":
+		leaderboardText.WriteString(b.localizer.Get(lang, "leaderboard_rating_title"))
+	default:
+		leaderboardText.WriteString(b.localizer.Get(lang, "leaderboard_title"))
+	}
 
 	rankEmojis := []string{"🥇", "🥈", "🥉"}
+	emojis := b.badgeEmojis(playerIDs(players))
+	now := time.Now()
 
 	for i, p := range players {
 		var rank string
@@ -221,27 +340,28 @@ func (b *Bot) handleLeaderboardCommand(message *tgbotapi.Message) {
 			rank = fmt.Sprintf("%d.", i+1)
 		}
 
-		// TANDA: Logika untuk mengambil dan menampilkan lencana ditambahkan di sini
-		
-		badgeDisplay := ""
-		if p.EquippedBadgeID != nil {
-			badge, err := b.db.GetBadgeByID(*p.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			playerBadges, _ := b.db.GetPlayerBadges(p.TelegramUserID)
-			if len(playerBadges) > 0 {
-				badgeDisplay = playerBadges[0].Emoji + " "
+		playerNameDisplay := withBadge(emojis[p.TelegramUserID], p.FirstName)
+
+		var entry string
+		switch mode {
+		case "wins":
+			winRate := 0.0
+			if p.GamesCounted > 0 {
+				winRate = float64(p.GamesWon) / float64(p.GamesCounted) * 100
 			}
+			entry = b.localizer.Get(lang, "leaderboard_wins_entry")
+			entry = strings.Replace(entry, "{wins}", strconv.Itoa(p.GamesWon), 1)
+			entry = strings.Replace(entry, "{win_rate}", fmt.Sprintf("%.1f", winRate), 1)
+		case "rating":
+			entry = b.localizer.Get(lang, "leaderboard_rating_entry")
+			entry = strings.Replace(entry, "{rating}", formatRating(p.SkillRating().Decayed(now)), 1)
+			entry = strings.Replace(entry, "{rated_games}", strconv.Itoa(p.RatedGames), 1)
+		default:
+			entry = b.localizer.Get(lang, "leaderboard_entry")
+			entry = strings.Replace(entry, "{points}", strconv.Itoa(p.Points), 1)
 		}
-		playerNameDisplay := badgeDisplay + html.EscapeString(p.FirstName)
-		// TANDA: Akhir dari blok yang ditambahkan
-
-		entry := b.localizer.Get(lang, "leaderboard_entry")
 		entry = strings.Replace(entry, "{rank_emoji}", rank, 1)
 		entry = strings.Replace(entry, "{name}", playerNameDisplay, 1)
-		entry = strings.Replace(entry, "{points}", strconv.Itoa(p.Points), 1)
 		leaderboardText.WriteString(entry)
 	}
 
@@ -1,11 +1,10 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"log"
-	"math/rand"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,283 +15,469 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (b *Bot) handleClueSubmission(message *tgbotapi.Message, player *db.Player, state *game.GameState, chatID int64, lang string) {
-	state.ClueGiverReminderTimer.Stop()
-	clueText := message.Text
-	if len(strings.Fields(clueText)) != 1 {
-		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_not_one_word"), true)
-		return
+// process menjalankan satu event di engine chat lalu menerapkan efeknya.
+// Selalu dipanggil dari mailbox chat tersebut, lewat dispatch atau post.
+func (b *Bot) process(chatID int64, ev game.Event) error {
+	b.mu.Lock()
+	engine, ok := b.games[chatID]
+	if !ok {
+		b.mu.Unlock()
+		return game.ErrGameNotActive
 	}
-	if strings.EqualFold(clueText, state.SecretWord) {
-		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_is_secret_word"), true)
-		return
+	effects, err := engine.Handle(ev)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(effects) > 0 {
+		b.saveGame(chatID)
 	}
 
-	state.Clue = clueText
-	state.Status = game.StatusWaitingForGuesses
-	log.Printf("Clue received for chat %d: '%s'", chatID, clueText)
-	b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_received"), false)
+	// Event lanjutan diproses langsung di pekerjaan ini, bukan dikirim ke
+	// mailbox sendiri yang bisa penuh selagi pekerjaan ini menunggunya.
+	var followUps []game.Event
+	for _, effect := range effects {
+		if next := b.applyEffect(chatID, effect); next != nil {
+			followUps = append(followUps, next)
+		}
+	}
+	for _, next := range followUps {
+		if err := b.process(chatID, next); err != nil {
+			log.Printf("Failed to process follow-up event in chat %d: %v", chatID, err)
+		}
+	}
+	return nil
+}
 
-	announcement := b.localizer.Get(lang, "clue_announcement_in_group")
-	announcement = strings.Replace(announcement, "{round}", strconv.Itoa(state.Round), 1)
-	announcement = strings.Replace(announcement, "{giver_name}", html.EscapeString(state.ClueGiver.FirstName), 1)
-	announcement = strings.Replace(announcement, "{clue}", strings.ToUpper(html.EscapeString(clueText)), 1)
+// applyEffect menerjemahkan satu efek engine menjadi panggilan Telegram atau
+// database. Event yang harus menyusul efek ini dikembalikan sebagai next.
+func (b *Bot) applyEffect(chatID int64, effect game.Effect) (next game.Event) {
+	lang := "id"
 
-	sentMsg, err := b.sendMessageAndGet(chatID, announcement, true)
-	if err != nil {
-		log.Printf("Failed to send clue announcement to chat %d: %v", chatID, err)
-		delete(b.gameStates, chatID)
-		return
-	}
+	switch e := effect.(type) {
+	case game.LobbyChanged:
+		b.updateLobbyMessage(chatID)
 
-	state.ClueMessageID = sentMsg.MessageID
-	state.GuessingStartTime = time.Now()
-	state.Timer = time.AfterFunc(60*time.Second, func() { b.handleTimesUp(chatID) })
-	state.GuessingTimeWarningTimer = time.AfterFunc(45*time.Second, func() { b.handleGuessingTimeWarning(chatID) })
-}
+	case game.GameStarted:
+		b.updateLobbyMessage(chatID)
+		if e.AutoStarted {
+			b.sendMessage(chatID, b.localizer.Get(lang, "game_auto_started"), true)
+		}
+		b.sendMessage(chatID, b.localizer.Get(lang, "game_started_announcement"), true)
+
+	case game.LobbyClosed:
+		key := "lobby_closed"
+		switch e.Reason {
+		case game.EndReasonExpired:
+			key = "lobby_expired"
+		case game.EndReasonAbandoned:
+			key = "lobby_closed_empty"
+		}
+		edit := tgbotapi.NewEditMessageText(chatID, e.LobbyMessageID, b.localizer.Get(lang, key))
+		edit.ParseMode = tgbotapi.ModeHTML
+		b.api.Request(edit)
+
+	case game.ArmTimer:
+		b.armTimer(chatID, e)
+
+	case game.StopTimer:
+		b.stopTimer(chatID, e.Timer)
+
+	case game.RoundStarted:
+		b.db.RecordWordUsage(chatID, e.SecretWord, time.Now())
+
+		announcement := b.localizer.Get(lang, "round_start_announcement")
+		announcement = strings.Replace(announcement, "{current_round}", strconv.Itoa(e.Round), 1)
+		announcement = strings.Replace(announcement, "{total_rounds}", strconv.Itoa(e.TotalRounds), 1)
+		announcement = strings.Replace(announcement, "{clue_giver_name}", b.displayName(e.ClueGiver), 1)
+		b.sendMessage(chatID, announcement, true)
+
+		if err := b.sendSecretWordPrompt(lang, chatID, e.ClueGiver, e.SecretWord, e.Taboo, e.RerollsLeft); err != nil {
+			text := strings.Replace(b.localizer.Get(lang, "pm_failed_turn_skipped"), "{name}", html.EscapeString(e.ClueGiver.FirstName), 1)
+			msg := tgbotapi.NewMessage(chatID, text)
+			msg.ParseMode = tgbotapi.ModeHTML
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL(b.localizer.Get(lang, "button_start_pm"), b.startPMURL(chatID)),
+			))
+			b.api.Send(msg)
+			next = game.SkipTurnEvent{Reason: game.SkipReasonPMFailed}
+		}
 
+	case game.WordRerolled:
+		log.Printf("Clue giver %s rerolled '%s' in chat %d", e.ClueGiver.FirstName, e.OldWord, chatID)
+		b.db.RecordWordReroll(chatID, e.ClueGiver.TelegramUserID, e.OldWord)
+		b.db.RecordWordUsage(chatID, e.NewWord, time.Now())
+		b.sendSecretWordPrompt(lang, chatID, e.ClueGiver, e.NewWord, e.Taboo, e.RerollsLeft)
 
-// TANDA: Fungsi ini diperbarui untuk menerapkan skor berbasis waktu
-func (b *Bot) handleGroupMessage(message *tgbotapi.Message, player *db.Player) {
-	chatID := message.Chat.ID
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-	if !ok || !state.IsActive || state.Status != game.StatusWaitingForGuesses {
-		return
-	}
+	case game.ClueReminder:
+		log.Printf("Sending clue giver reminder to player %d for game in chat %d", e.ClueGiver.TelegramUserID, chatID)
+		text := b.localizer.Get(lang, "clue_giver_reminder")
+		text = strings.Replace(text, "{name}", e.ClueGiver.FirstName, 1)
+		b.sendMessage(e.ClueGiver.TelegramUserID, text, true)
 
-	if _, isParticipant := state.Players[player.TelegramUserID]; !isParticipant {
-		log.Printf("Non-participant %s tried to guess.", player.FirstName)
-		lang := b.getUserLang(message.From)
-		warningText := b.localizer.Get(lang, "warning_not_participant")
-		b.sendMessage(player.TelegramUserID, warningText, true)
-		return
-	}
+	case game.ClueAccepted:
+		log.Printf("Clue received for chat %d: '%s'", chatID, e.Clue)
+		b.sendMessage(e.ClueGiver.TelegramUserID, b.localizer.Get(lang, "clue_received"), false)
 
-	if message.ReplyToMessage == nil || message.ReplyToMessage.MessageID != state.ClueMessageID {
-		return
-	}
+		announcement := b.clueAnnouncement(lang, e.Round, e.ClueGiver, e.Clue, e.GuessSeconds)
+		sentMsg, err := b.sendMessageAndGet(chatID, announcement, true)
+		if err != nil {
+			log.Printf("Failed to send clue announcement to chat %d, retrying: %v", chatID, err)
+			sentMsg, err = b.sendMessageAndGet(chatID, announcement, true)
+		}
+		if err != nil {
+			// Tanpa petunjuk tidak ada yang bisa menebak; permainan diakhiri
+			// lewat engine supaya skor yang sudah didapat tetap dibayarkan.
+			log.Printf("Failed to send clue announcement to chat %d, ending game: %v", chatID, err)
+			return game.EndEvent{}
+		}
+		b.mu.Lock()
+		if engine, ok := b.games[chatID]; ok {
+			engine.State.ClueMessageID = sentMsg.MessageID
+		}
+		b.mu.Unlock()
+		b.saveGame(chatID)
+
+	case game.CloseGuess:
+		text := b.localizer.Get(lang, "guess_almost")
+		text = strings.Replace(text, "{name}", html.EscapeString(e.Player.FirstName), 1)
+		b.sendMessage(e.Player.TelegramUserID, text, true)
+
+	case game.WrongGuess:
+		var wrongGuessesText strings.Builder
+		for _, wg := range e.WrongGuesses {
+			wrongGuessesText.WriteString(fmt.Sprintf("❌ %s\n", html.EscapeString(wg)))
+		}
+		fullText := fmt.Sprintf("%s\n\n<b>Tebakan salah:</b>\n%s", b.clueAnnouncement(lang, e.Round, e.ClueGiver, e.Clue, e.GuessSeconds), wrongGuessesText.String())
 
-	if player.TelegramUserID == state.ClueGiver.TelegramUserID {
-		log.Printf("Clue giver %s tried to guess.", player.FirstName)
-		return
-	}
+		b.mu.RLock()
+		clueMessageID := 0
+		if engine, ok := b.games[chatID]; ok {
+			clueMessageID = engine.State.ClueMessageID
+		}
+		b.mu.RUnlock()
 
-	guess := message.Text
-	lang := b.getUserLang(message.From)
-	log.Printf("Guess received in chat %d from %s: '%s'", chatID, player.FirstName, guess)
+		editMsg := tgbotapi.NewEditMessageText(chatID, clueMessageID, fullText)
+		editMsg.ParseMode = tgbotapi.ModeHTML
+		b.api.Send(editMsg)
 
-	b.api.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
+	case game.RoundWon:
+		log.Printf("Correct guess by %s in chat %d.", e.Winner.FirstName, chatID)
+		b.checkAchievements(e.Winner.TelegramUserID, chatID, achievementEvent{GuessSeconds: e.TimeTaken})
 
-	if strings.EqualFold(guess, state.SecretWord) {
-		log.Printf("Correct guess by %s in chat %d.", player.FirstName, chatID)
-		state.Timer.Stop()
-		if state.GuessingTimeWarningTimer != nil {
-			state.GuessingTimeWarningTimer.Stop()
+		responseText := b.localizer.Get(lang, "round_won_announcement")
+		responseText = strings.Replace(responseText, "{winner_name}", b.displayName(e.Winner), 1)
+		responseText = strings.Replace(responseText, "{word}", strings.ToUpper(e.Word), 1)
+		responseText = strings.Replace(responseText, "{points}", strconv.Itoa(e.Points), 1)
+		if e.ClueGiverPoints > 0 {
+			giverText := b.localizer.Get(lang, "taboo_clue_giver_points")
+			giverText = strings.Replace(giverText, "{name}", html.EscapeString(e.ClueGiver.FirstName), 1)
+			giverText = strings.Replace(giverText, "{points}", strconv.Itoa(e.ClueGiverPoints), 1)
+			responseText += "\n" + giverText
 		}
+		b.sendMessage(chatID, responseText, true)
 
-		// TANDA: Logika skor berbasis waktu hanya untuk Penebak
-		timeTaken := time.Since(state.GuessingStartTime).Seconds()
-		var points int
-		if timeTaken <= 15 {
-			points = 20
-		} else if timeTaken <= 30 {
-			points = 15
-		} else if timeTaken <= 45 {
-			points = 10
-		} else {
-			points = 5
+	case game.GuessWarning:
+		log.Printf("Sending time warning for game in chat %d", chatID)
+		text := b.localizer.Get(lang, "guess_time_warning")
+		text = strings.Replace(text, "{seconds}", strconv.Itoa(e.SecondsLeft), 1)
+		b.sendMessage(chatID, text, true)
+
+	case game.TimesUp:
+		log.Printf("Time's up for game in chat %d. Word was %s", chatID, e.Word)
+		responseText := b.localizer.Get(lang, "times_up")
+		responseText = strings.Replace(responseText, "{word}", strings.ToUpper(e.Word), 1)
+		b.sendMessage(chatID, responseText, true)
+
+	case game.TurnSkipped:
+		log.Printf("Turn of %s skipped in chat %d (%s)", e.ClueGiver.FirstName, chatID, e.Reason)
+		if e.Reason == game.SkipReasonTimeout {
+			text := b.localizer.Get(lang, "clue_giver_timeout_skip")
+			text = strings.Replace(text, "{name}", html.EscapeString(e.ClueGiver.FirstName), 1)
+			b.sendMessage(chatID, text, true)
 		}
 
-		// Tambahkan poin HANYA untuk Penebak
-		state.SessionScores[player.TelegramUserID] += points
-		
-		go b.db.IncrementPlayerStats(player.TelegramUserID, "words_guessed_count", 1)
-		// Catat bahwa si Pemberi Petunjuk berhasil memberikan petunjuk
-		go b.db.IncrementPlayerStats(state.ClueGiver.TelegramUserID, "clue_success_count", 1)
-		// Perbarui rekor tebakan tercepat si Penebak
-		go b.db.UpdatePlayerFastestGuess(player.TelegramUserID, timeTaken)
-		go b.checkAndAwardAchievements(player.TelegramUserID, chatID, player.FirstName, timeTaken)
-
-		fullPlayer, _ := b.db.GetPlayerByID(player.TelegramUserID)
-		
-		badgeDisplay := ""
-		if fullPlayer.EquippedBadgeID != nil {
-			// Prioritaskan lencana yang dipakai
-			badge, err := b.db.GetBadgeByID(*fullPlayer.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			// Fallback ke lencana pertama jika tidak ada yang dipakai
-			playerBadges, _ := b.db.GetPlayerBadges(player.TelegramUserID)
-			if len(playerBadges) > 0 {
-				badgeDisplay = playerBadges[0].Emoji + " "
+	case game.PlayerJoined:
+		log.Printf("Player %s joined the running game in chat %d", e.Player.FirstName, chatID)
+		text := b.localizer.Get(lang, "player_joined_running_game")
+		text = strings.Replace(text, "{name}", b.displayName(e.Player), 1)
+		b.sendMessage(chatID, text, true)
+
+	case game.PlayerLeft:
+		log.Printf("Player %s left the game in chat %d with %d points", e.Player.FirstName, chatID, e.Points)
+		text := b.localizer.Get(lang, "player_left_game")
+		text = strings.Replace(text, "{name}", html.EscapeString(e.Player.FirstName), 1)
+		text = strings.Replace(text, "{points}", strconv.Itoa(e.Points), 1)
+		b.sendMessage(chatID, text, true)
+
+	case game.HostChanged:
+		text := b.localizer.Get(lang, "host_transferred")
+		text = strings.Replace(text, "{host_name}", html.EscapeString(e.Host.FirstName), 1)
+		b.sendMessage(chatID, text, true)
+
+	case game.PlayerBenched:
+		log.Printf("Player %s removed from turn order in chat %d after %d missed turns", e.Player.FirstName, chatID, e.MissedTurns)
+		text := b.localizer.Get(lang, "player_removed_from_turns")
+		text = strings.Replace(text, "{name}", html.EscapeString(e.Player.FirstName), 1)
+		text = strings.Replace(text, "{missed}", strconv.Itoa(e.MissedTurns), 1)
+		b.sendMessage(chatID, text, true)
+
+	case game.RoundScoreboard:
+		var scoreboard strings.Builder
+		scoreboard.WriteString(b.localizer.Get(lang, "end_of_round_scoreboard_title"))
+		scoreboard.WriteString(b.scoreboardEntries(lang, e.Standings))
+		b.sendMessage(chatID, scoreboard.String(), true)
+
+	case game.GameOver:
+		b.removeGame(chatID)
+		log.Printf("Game ended in chat %d.", chatID)
+		if !e.Started {
+			return
+		}
+		if e.Record != nil {
+			record := e.Record
+			b.background(func() {
+				if err := b.db.SaveGameRecord(record); err != nil {
+					log.Printf("Failed to save history of game %s in chat %d: %v", record.Game.ID, chatID, err)
+				}
+			})
+			for _, p := range e.Record.Players {
+				b.checkAchievements(p.PlayerID, chatID, achievementEvent{Game: gameFeatsFor(e.Record, p.PlayerID)})
 			}
 		}
-		winnerNameDisplay := badgeDisplay + html.EscapeString(player.FirstName)
 
-		responseText := b.localizer.Get(lang, "round_won_announcement")
-		responseText = strings.Replace(responseText, "{winner_name}", winnerNameDisplay, 1) // Gunakan nama yang sudah ada lencananya
-		responseText = strings.Replace(responseText, "{word}", strings.ToUpper(state.SecretWord), 1)
-		responseText = strings.Replace(responseText, "{points}", strconv.Itoa(points), 1)
-		b.sendMessage(chatID, responseText, true)
-		b.handleEndOfRound(chatID)
-		return
-	}
+		var finalMsg string
+		switch e.Reason {
+		case game.EndReasonHost:
+			finalMsg = b.localizer.Get(lang, "game_ended_by_host")
+		case game.EndReasonAbandoned:
+			finalMsg = b.localizer.Get(lang, "game_abandoned")
+		default:
+			finalMsg = b.localizer.Get(lang, "game_over_announcement")
+			finalMsg = strings.Replace(finalMsg, "{total_rounds}", strconv.Itoa(e.Rounds), 1)
+		}
+		finalMsg += b.scoreboardEntries(lang, e.Standings)
+
+		switch len(e.Winners) {
+		case 0:
+		case 1:
+			winnerAnnounce := b.localizer.Get(lang, "final_winner_announcement")
+			winnerAnnounce = strings.Replace(winnerAnnounce, "{winner_name}", b.displayName(e.Winners[0]), 1)
+			finalMsg += winnerAnnounce
+		default:
+			names := b.displayNames(e.Winners)
+			winnerNames := make([]string, len(e.Winners))
+			for i, w := range e.Winners {
+				winnerNames[i] = "<b>" + names[w.TelegramUserID] + "</b>"
+			}
+			finalMsg += strings.Replace(b.localizer.Get(lang, "final_tie_announcement"), "{winner_names}", strings.Join(winnerNames, ", "), 1)
+		}
+		if len(e.Winners) > 0 && !e.WinCounted && e.Reason != game.EndReasonAbandoned {
+			finalMsg += b.localizer.Get(lang, "final_win_not_counted")
+		}
+		b.sendMessage(chatID, finalMsg, true)
 
-	log.Printf("Incorrect guess by %s in chat %d.", player.FirstName, chatID)
-	state.WrongGuesses = append(state.WrongGuesses, guess)
+	case game.IncrementStat:
+		b.background(func() {
+			if err := b.db.IncrementPlayerStats(e.PlayerID, e.Field, e.Value); err != nil {
+				return
+			}
+			b.checkAchievements(e.PlayerID, chatID, achievementEvent{})
+		})
 
-	var wrongGuessesText strings.Builder
-	for _, wg := range state.WrongGuesses {
-		wrongGuessesText.WriteString(fmt.Sprintf("❌ %s\n", html.EscapeString(wg)))
-	}
+	case game.RecordGuessTime:
+		b.background(func() { b.db.UpdatePlayerFastestGuess(e.PlayerID, e.Seconds) })
 
-	originalAnnouncement := b.localizer.Get(lang, "clue_announcement_in_group")
-	originalAnnouncement = strings.Replace(originalAnnouncement, "{round}", strconv.Itoa(state.Round), 1)
-	originalAnnouncement = strings.Replace(originalAnnouncement, "{giver_name}", html.EscapeString(state.ClueGiver.FirstName), 1)
-	originalAnnouncement = strings.Replace(originalAnnouncement, "{clue}", strings.ToUpper(html.EscapeString(state.Clue)), 1)
+	case game.GameResult:
+		b.background(func() {
+			if err := b.db.RecordGameResult(e.PlayerID, e.Won); err != nil {
+				return
+			}
+			b.checkAchievements(e.PlayerID, chatID, achievementEvent{})
+		})
 
-	fullText := fmt.Sprintf("%s\n\n<b>Tebakan salah:</b>\n%s", originalAnnouncement, wrongGuessesText.String())
+	case game.RateGame:
+		b.background(func() { b.updateRatings(chatID, e.Standings) })
 
-	editMsg := tgbotapi.NewEditMessageText(chatID, state.ClueMessageID, fullText)
-	editMsg.ParseMode = tgbotapi.ModeHTML
-	b.api.Send(editMsg)
+	case game.AwardPoints:
+		if err := b.db.AddPoints(e.PlayerID, e.Points, db.LedgerGamePayout, strconv.FormatInt(chatID, 10)); err != nil {
+			log.Printf("Failed to add %d points to player %d: %v", e.Points, e.PlayerID, err)
+		}
+	}
+	return
 }
 
+// removeGame menghapus permainan dari memori beserta semua timernya.
+func (b *Bot) removeGame(chatID int64) {
+	b.mu.Lock()
+	delete(b.games, chatID)
+	b.mu.Unlock()
+	b.stopAllTimers(chatID)
+	b.db.DeleteGameSnapshot(db.SnapshotGroup, chatID)
+}
 
-// TANDA: Fungsi ini dirombak untuk menambahkan semua skor sesi ke skor global
-func (b *Bot) endGame(chatID int64, reason string) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
+func (b *Bot) clueAnnouncement(lang string, round int, clueGiver *db.Player, clue string, guessSeconds int) string {
+	announcement := b.localizer.Get(lang, "clue_announcement_in_group")
+	announcement = strings.Replace(announcement, "{seconds}", strconv.Itoa(guessSeconds), 1)
+	announcement = strings.Replace(announcement, "{round}", strconv.Itoa(round), 1)
+	announcement = strings.Replace(announcement, "{giver_name}", html.EscapeString(clueGiver.FirstName), 1)
+	announcement = strings.Replace(announcement, "{clue}", strings.ToUpper(html.EscapeString(clue)), 1)
+	return announcement
+}
 
-	if !ok {
-		return
+func (b *Bot) scoreboardEntries(lang string, standings []game.Standing) string {
+	players := make([]*db.Player, len(standings))
+	for i, st := range standings {
+		players[i] = st.Player
 	}
+	names := b.displayNames(players)
 
-	if state.ClueGiverReminderTimer != nil {
-		state.ClueGiverReminderTimer.Stop()
-	}
-	if state.GuessingTimeWarningTimer != nil {
-		state.GuessingTimeWarningTimer.Stop()
-	}
-	if state.Timer != nil {
-		state.Timer.Stop()
+	var scoreboard strings.Builder
+	for _, st := range standings {
+		entry := b.localizer.Get(lang, "end_of_round_scoreboard_entry")
+		entry = strings.Replace(entry, "{player_name}", names[st.Player.TelegramUserID], 1)
+		entry = strings.Replace(entry, "{points}", strconv.Itoa(st.Points), 1)
+		scoreboard.WriteString(entry)
 	}
+	return scoreboard.String()
+}
 
-	for _, p := range state.Players {
-		go b.db.IncrementPlayerStats(p.TelegramUserID, "games_played", 1)
+// sendSecretWordPrompt mengirim kata rahasia ke Pemberi Petunjuk lewat PM,
+// lengkap dengan daftar kata terlarang dan tombol ganti kata jika jatahnya masih ada.
+func (b *Bot) sendSecretWordPrompt(lang string, chatID int64, giver *db.Player, word string, taboo []string, rerollsLeft int) error {
+	promptText := b.localizer.Get(lang, "secret_word_prompt")
+	promptText = strings.Replace(promptText, "{name}", html.EscapeString(giver.FirstName), -1)
+	promptText = strings.Replace(promptText, "{word}", word, -1)
+	if title := b.gameTitle(chatID); title != "" {
+		promptText += "\n\n" + strings.Replace(b.localizer.Get(lang, "secret_word_group"), "{group}", html.EscapeString(title), 1)
+	}
+	if len(taboo) > 0 {
+		tabooText := b.localizer.Get(lang, "secret_word_taboo_list")
+		tabooText = strings.Replace(tabooText, "{taboo}", html.EscapeString(strings.Join(taboo, ", ")), 1)
+		promptText += "\n\n" + tabooText
+	}
+
+	msg := tgbotapi.NewMessage(giver.TelegramUserID, promptText)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if rerollsLeft > 0 {
+		label := strings.Replace(b.localizer.Get(lang, "button_reroll_word"), "{left}", strconv.Itoa(rerollsLeft), 1)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("reroll_%d", chatID)),
+			),
+		)
+	}
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		log.Printf("Failed to send secret word to player %d: %v", giver.TelegramUserID, err)
+		if isForbidden(err) {
+			b.markPMReachable(giver.TelegramUserID, false)
+		}
+		return err
 	}
+	b.rememberCluePrompt(giver.TelegramUserID, chatID, sent.MessageID)
+	return nil
+}
 
-	lang := "id"
-	finalMsg := reason
-
-	// TANDA: Logika penambahan poin ke DB dimulai di sini
-	log.Printf("Game ended in chat %d. Adding session points to global score.", chatID)
-	for playerID, points := range state.SessionScores {
-		if points > 0 {
-			err := b.db.AddPoints(playerID, points)
-			if err != nil {
-				log.Printf("Failed to add %d points to player %d: %v", points, playerID, err)
-			}
-		}
+// handleRerollCallback mengganti kata rahasia saat Pemberi Petunjuk menekan tombol ganti kata.
+func (b *Bot) handleRerollCallback(query *tgbotapi.CallbackQuery) {
+	lang := b.getUserLang(query.From)
+	chatID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "reroll_"), 10, 64)
+	if err != nil {
+		return
 	}
-	// TANDA: Logika penambahan poin ke DB berakhir di sini
 
-	// Tampilkan papan skor akhir
-	var scoreboard strings.Builder
-	players := make([]*db.Player, 0, len(state.Players))
-	for _, p := range state.Players {
-		players = append(players, p)
+	err = b.dispatch(chatID, game.RerollEvent{PlayerID: query.From.ID})
+	switch {
+	case errors.Is(err, game.ErrNoRerollsLeft):
+		b.answerCallback(query.ID, b.localizer.Get(lang, "reroll_none_left"), true)
+		return
+	case err != nil:
+		b.answerCallback(query.ID, b.localizer.Get(lang, "reroll_not_available"), true)
+		return
 	}
-	sort.Slice(players, func(i, j int) bool {
-		return state.SessionScores[players[i].TelegramUserID] > state.SessionScores[players[j].TelegramUserID]
-	})
-	for _, p := range players {
-		// TANDA: Logika lencana ditambahkan di sini
-		
-		fullPlayer, _ := b.db.GetPlayerByID(p.TelegramUserID)
-		badgeDisplay := ""
-		if fullPlayer.EquippedBadgeID != nil {
-			badge, err := b.db.GetBadgeByID(*fullPlayer.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			playerBadges, _ := b.db.GetPlayerBadges(p.TelegramUserID)
-			if len(playerBadges) > 0 {
-				badgeDisplay = playerBadges[0].Emoji + " "
-			}
-		}
-		playerNameDisplay := badgeDisplay + html.EscapeString(p.FirstName)
 
-		entry := b.localizer.Get(lang, "end_of_round_scoreboard_entry")
-		entry = strings.Replace(entry, "{player_name}", playerNameDisplay, 1)
-		entry = strings.Replace(entry, "{points}", strconv.Itoa(state.SessionScores[p.TelegramUserID]), 1)
-		scoreboard.WriteString(entry)
+	// Kata lama sudah tidak berlaku, jadi tombol di pesan lama dihapus.
+	b.api.Request(tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+	b.answerCallback(query.ID, b.localizer.Get(lang, "reroll_done"), false)
+}
+
+func (b *Bot) handleClueSubmission(message *tgbotapi.Message, player *db.Player, chatID int64, lang string) {
+	err := b.dispatch(chatID, game.ClueEvent{PlayerID: player.TelegramUserID, Text: message.Text})
+	switch {
+	case errors.Is(err, game.ErrClueNotOneWord):
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_not_one_word"), true)
+	case errors.Is(err, game.ErrClueIsSecretWord):
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_is_secret_word"), true)
+	case errors.Is(err, game.ErrClueContainsSecretWord):
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_contains_secret_word"), true)
+	case errors.Is(err, game.ErrClueSharesStem):
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_shares_stem"), true)
+	case errors.Is(err, game.ErrClueIsTaboo):
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "clue_invalid_taboo"), true)
+	case err != nil:
+		log.Printf("Clue from %s for chat %d rejected: %v", player.FirstName, chatID, err)
 	}
-	finalMsg += scoreboard.String()
-
-	var winner *db.Player
-	maxPoints := -1
-	for playerID, points := range state.SessionScores {
-		if points > maxPoints {
-			maxPoints = points
-			winner = state.Players[playerID]
-		}
+}
+
+func (b *Bot) handleGroupMessage(message *tgbotapi.Message, player *db.Player) {
+	chatID := message.Chat.ID
+	b.mu.RLock()
+	engine, ok := b.games[chatID]
+	clueMessageID := 0
+	if ok {
+		clueMessageID = engine.State.ClueMessageID
 	}
-	if winner != nil {
-		// TANDA: Logika yang sama untuk pemenang utama
-		fullWinner, _ := b.db.GetPlayerByID(winner.TelegramUserID)
-		badgeDisplay := ""
-		if fullWinner.EquippedBadgeID != nil {
-			badge, err := b.db.GetBadgeByID(*fullWinner.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			winnerBadges, _ := b.db.GetPlayerBadges(winner.TelegramUserID)
-			if len(winnerBadges) > 0 {
-				badgeDisplay = winnerBadges[0].Emoji + " "
-			}
-		}
-		winnerNameDisplay := badgeDisplay + html.EscapeString(winner.FirstName)
-	
-		winnerAnnounce := b.localizer.Get(lang, "final_winner_announcement")
-		winnerAnnounce = strings.Replace(winnerAnnounce, "{winner_name}", winnerNameDisplay, 1)
-		finalMsg += winnerAnnounce
+	b.mu.RUnlock()
+	if !ok || clueMessageID == 0 {
+		return
 	}
-	
 
-	b.sendMessage(chatID, finalMsg, true)
+	if message.ReplyToMessage == nil || message.ReplyToMessage.MessageID != clueMessageID {
+		return
+	}
 
-	b.mu.Lock()
-	delete(b.gameStates, chatID)
-	b.mu.Unlock()
+	log.Printf("Guess received in chat %d from %s: '%s'", chatID, player.FirstName, message.Text)
+	err := b.dispatch(chatID, game.GuessEvent{Player: player, Text: message.Text})
+	switch {
+	case errors.Is(err, game.ErrNotParticipant):
+		log.Printf("Non-participant %s tried to guess.", player.FirstName)
+		lang := b.getUserLang(message.From)
+		b.sendMessage(player.TelegramUserID, b.localizer.Get(lang, "warning_not_participant"), true)
+		return
+	case errors.Is(err, game.ErrClueGiverGuess):
+		log.Printf("Clue giver %s tried to guess.", player.FirstName)
+		return
+	case err != nil:
+		return
+	}
 
+	b.api.Request(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
 }
 
-
 func (b *Bot) handleSoloGuess(message *tgbotapi.Message, player *db.Player, state *game.SoloGameState, lang string) {
 	guess := message.Text
-	if strings.EqualFold(guess, state.CurrentWord.Word) {
+	answer := db.Word{Word: state.CurrentWord.Word, Aliases: state.CurrentWord.Aliases}
+	if game.MatchGuess(guess, answer) == game.MatchExact {
 		score := 100 - (state.HintsGiven-1)*10
 		if score < 10 {
 			score = 10
 		}
-		err := b.db.AddPoints(player.TelegramUserID, score)
+		err := b.db.AddPoints(player.TelegramUserID, score, db.LedgerSoloWin, "")
 		if err != nil {
 			log.Printf("Failed to add points for solo game winner %d", player.TelegramUserID)
 		}
+		b.checkAchievements(player.TelegramUserID, message.Chat.ID, achievementEvent{SoloHints: state.HintsGiven})
 		responseText := b.localizer.Get(lang, "solo_guess_correct")
 		responseText = strings.Replace(responseText, "{hints_given}", strconv.Itoa(state.HintsGiven), 1)
 		responseText = strings.Replace(responseText, "{word}", strings.ToUpper(state.CurrentWord.Word), 1)
 		responseText = strings.Replace(responseText, "{score}", strconv.Itoa(score), 1)
 		b.sendMessage(message.Chat.ID, responseText, true)
+
+		b.mu.Lock()
 		delete(b.soloGameStates, player.TelegramUserID)
+		b.mu.Unlock()
+		b.db.DeleteGameSnapshot(db.SnapshotSolo, player.TelegramUserID)
 	} else {
 		if state.HintsGiven < len(state.CurrentWord.Hints) {
 			state.HintsGiven++
@@ -301,6 +486,7 @@ func (b *Bot) handleSoloGuess(message *tgbotapi.Message, player *db.Player, stat
 			responseText = strings.Replace(responseText, "{hint_number}", strconv.Itoa(state.HintsGiven), 1)
 			responseText = strings.Replace(responseText, "{hint}", nextHint, 1)
 			b.sendMessage(message.Chat.ID, responseText, true)
+			b.saveSoloGame(player.TelegramUserID)
 		} else {
 			responseText := b.localizer.Get(lang, "solo_no_more_hints")
 			responseText = strings.Replace(responseText, "{word}", strings.ToUpper(state.CurrentWord.Word), 1)
@@ -309,172 +495,15 @@ func (b *Bot) handleSoloGuess(message *tgbotapi.Message, player *db.Player, stat
 			b.mu.Lock()
 			delete(b.soloGameStates, player.TelegramUserID)
 			b.mu.Unlock()
+			b.db.DeleteGameSnapshot(db.SnapshotSolo, player.TelegramUserID)
 		}
 	}
 }
 
-func (b *Bot) startRound(chatID int64) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-	if !ok {
-		return
-	}
-
-	state.Round++
-	state.CurrentTurnIndex = (state.Round - 1) % len(state.TurnOrder)
-	clueGiver := state.TurnOrder[state.CurrentTurnIndex]
-	go b.db.IncrementPlayerStats(clueGiver.TelegramUserID, "clue_given_count", 1)
-	state.ClueGiver = clueGiver
-	state.Status = game.StatusWaitingForClue
-	state.WrongGuesses = make([]string, 0)
-
-	lang := "id"
-	
-	fullClueGiver, _ := b.db.GetPlayerByID(clueGiver.TelegramUserID)
-	badgeDisplay := ""
-	if fullClueGiver.EquippedBadgeID != nil {
-    badge, err := b.db.GetBadgeByID(*fullClueGiver.EquippedBadgeID)
-    if err == nil {
-        badgeDisplay = badge.Emoji + " "
-    }
-	} else {
-    clueGiverBadges, _ := b.db.GetPlayerBadges(clueGiver.TelegramUserID)
-    if len(clueGiverBadges) > 0 {
-        badgeDisplay = clueGiverBadges[0].Emoji + " "
-    }
-	}
-	clueGiverNameDisplay := badgeDisplay + html.EscapeString(clueGiver.FirstName)
-	// TANDA: Akhir dari blok yang ditambahkan
-
-	announcement := b.localizer.Get(lang, "round_start_announcement")
-	announcement = strings.Replace(announcement, "{current_round}", strconv.Itoa(state.Round), 1)
-	announcement = strings.Replace(announcement, "{total_rounds}", strconv.Itoa(state.TotalRounds), 1)
-	announcement = strings.Replace(announcement, "{clue_giver_name}", clueGiverNameDisplay, 1) // Gunakan nama yang sudah ada lencananya
-	b.sendMessage(chatID, announcement, true)
-
-
-	secretWord := game.WordList[rand.Intn(len(game.WordList))]
-	state.SecretWord = secretWord
-
-	promptText := b.localizer.Get(lang, "secret_word_prompt")
-	promptText = strings.Replace(promptText, "{name}", html.EscapeString(clueGiver.FirstName), -1)
-	promptText = strings.Replace(promptText, "{word}", secretWord, -1)
-	err := b.sendMessage(clueGiver.TelegramUserID, promptText, true)
-	if err != nil {
-		b.sendMessage(chatID, fmt.Sprintf("Gagal mengirim PM ke %s, giliran dilewati.", clueGiver.FirstName), false)
-		time.Sleep(2 * time.Second)
-		b.handleEndOfRound(chatID)
-		return
-	}
-
-	state.ClueGiverReminderTimer = time.AfterFunc(90*time.Second, func() {
-		b.handleClueGiverReminder(chatID, clueGiver.TelegramUserID)
-	})
-}
-
-func (b *Bot) handleEndOfRound(chatID int64) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-
-	if !ok {
-		return
-	}
-
-	var scoreboard strings.Builder
-	scoreboard.WriteString(b.localizer.Get("id", "end_of_round_scoreboard_title"))
-	players := make([]*db.Player, 0, len(state.Players))
-	for _, p := range state.Players {
-		players = append(players, p)
-	}
-	sort.Slice(players, func(i, j int) bool {
-		return state.SessionScores[players[i].TelegramUserID] > state.SessionScores[players[j].TelegramUserID]
-	})
-	for _, p := range players {
-		// TANDA: Logika untuk mengambil dan menampilkan lencana ditambahkan di sini
-		
-		fullPlayer, _ := b.db.GetPlayerByID(p.TelegramUserID)
-		badgeDisplay := ""
-		if fullPlayer.EquippedBadgeID != nil {
-			badge, err := b.db.GetBadgeByID(*fullPlayer.EquippedBadgeID)
-			if err == nil {
-				badgeDisplay = badge.Emoji + " "
-			}
-		} else {
-			playerBadges, _ := b.db.GetPlayerBadges(p.TelegramUserID)
-			if len(playerBadges) > 0 {
-				badgeDisplay = playerBadges[0].Emoji + " "
-			}
-		}
-		playerNameDisplay := badgeDisplay + html.EscapeString(p.FirstName)
-		// TANDA: Akhir dari blok yang ditambahkan
-
-		entry := b.localizer.Get("id", "end_of_round_scoreboard_entry")
-		entry = strings.Replace(entry, "{player_name}", playerNameDisplay, 1)
-		entry = strings.Replace(entry, "{points}", strconv.Itoa(state.SessionScores[p.TelegramUserID]), 1)
-		scoreboard.WriteString(entry)
-	}
-	b.sendMessage(chatID, scoreboard.String(), true)
-	time.Sleep(4 * time.Second)
-
-	if state.Round >= state.TotalRounds {
-		reason := b.localizer.Get("id", "game_over_announcement")
-		reason = strings.Replace(reason, "{total_rounds}", strconv.Itoa(state.Round), 1)
-		b.endGame(chatID, reason)
-	} else {
-		b.startRound(chatID)
-	}
-}
-
-func (b *Bot) handleTimesUp(chatID int64) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-	if !ok || !state.IsActive || state.Status != game.StatusWaitingForGuesses {
-		return
-	}
-	if state.GuessingTimeWarningTimer != nil {
-		state.GuessingTimeWarningTimer.Stop()
-	}
-	log.Printf("Time's up for game in chat %d. Word was %s", chatID, state.SecretWord)
-	lang := "id"
-	responseText := b.localizer.Get(lang, "times_up")
-	responseText = strings.Replace(responseText, "{word}", strings.ToUpper(state.SecretWord), 1)
-	b.sendMessage(chatID, responseText, true)
-	b.handleEndOfRound(chatID)
-}
-
-func (b *Bot) handleGuessingTimeWarning(chatID int64) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-	if !ok || !state.IsActive || state.Status != game.StatusWaitingForGuesses {
-		return
-	}
-	log.Printf("Sending time warning for game in chat %d", chatID)
-	lang := "id"
-	b.sendMessage(chatID, b.localizer.Get(lang, "guess_time_warning"), true)
-}
-
-func (b *Bot) handleClueGiverReminder(chatID int64, playerID int64) {
-	b.mu.RLock()
-	state, ok := b.gameStates[chatID]
-	b.mu.RUnlock()
-	if !ok || !state.IsActive || state.Status != game.StatusWaitingForClue {
-		return
-	}
-	log.Printf("Sending clue giver reminder to player %d for game in chat %d", playerID, chatID)
-	lang := "id"
-	text := b.localizer.Get(lang, "clue_giver_reminder")
-	text = strings.Replace(text, "{name}", state.ClueGiver.FirstName, 1)
-	b.sendMessage(playerID, text, true)
-}
-
 func (b *Bot) startSoloGame(chatID int64, player *db.Player, lang string) {
-	rand.Seed(time.Now().UnixNano())
-	wordData := game.SoloWordList[rand.Intn(len(game.SoloWordList))]
-	
+	word := b.catalog.PickSolo()
+	wordData := game.WordData{Word: word.Word, Aliases: word.Aliases, Hints: word.Hints}
+
 	b.mu.Lock()
 	b.soloGameStates[player.TelegramUserID] = &game.SoloGameState{
 		UserID:      player.TelegramUserID,
@@ -483,32 +512,11 @@ func (b *Bot) startSoloGame(chatID int64, player *db.Player, lang string) {
 		HintsGiven:  1,
 	}
 	b.mu.Unlock()
-	
+	b.saveSoloGame(player.TelegramUserID)
+
 	b.sendMessage(chatID, b.localizer.Get(lang, "solo_game_started"), false)
 	time.Sleep(1 * time.Second)
 	firstHintText := b.localizer.Get(lang, "solo_first_hint")
 	firstHintText = strings.Replace(firstHintText, "{hint}", wordData.Hints[0], 1)
 	b.sendMessage(chatID, firstHintText, true)
 }
-
-func (b *Bot) startGame(chatID int64) {
-	b.mu.RLock()
-	state := b.gameStates[chatID]
-	b.mu.RUnlock()
-	lang := "id"
-
-	msg := tgbotapi.NewEditMessageReplyMarkup(chatID, state.LobbyMessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
-	b.api.Send(msg)
-
-	for _, p := range state.Players {
-		state.TurnOrder = append(state.TurnOrder, p)
-	}
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(state.TurnOrder), func(i, j int) {
-		state.TurnOrder[i], state.TurnOrder[j] = state.TurnOrder[j], state.TurnOrder[i]
-	})
-
-	b.sendMessage(chatID, b.localizer.Get(lang, "game_started_announcement"), true)
-	time.Sleep(2 * time.Second)
-	b.startRound(chatID)
-}
\ No newline at end of file
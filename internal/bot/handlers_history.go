@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var errGameNotInChat = errors.New("game belongs to another chat")
+
+// historyLimit adalah jumlah permainan terakhir yang ditampilkan oleh /history.
+const historyLimit = 10
+
+// handleHistoryCommand menampilkan permainan terakhir di grup beserta tombol untuk melihat detailnya.
+func (b *Bot) handleHistoryCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	lang := "id"
+	if message.Chat.IsPrivate() {
+		b.sendMessage(chatID, b.localizer.Get(b.getUserLang(message.From), "group_chat_only"), false)
+		return
+	}
+
+	text, keyboard, err := b.historyList(chatID, lang)
+	if err != nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "history_fetch_fail"), false)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	b.api.Send(msg)
+}
+
+// handleHistoryCallback menangani tombol "history_list" dan "history_game_<id>".
+func (b *Bot) handleHistoryCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	lang := "id"
+
+	var text string
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	var err error
+	if gameID := strings.TrimPrefix(query.Data, "history_game_"); gameID != query.Data {
+		text, keyboard, err = b.historyDetail(chatID, gameID, lang)
+	} else {
+		text, keyboard, err = b.historyList(chatID, lang)
+	}
+	if err != nil {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "history_fetch_fail"), true)
+		return
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, text)
+	editMsg.ParseMode = tgbotapi.ModeHTML
+	editMsg.ReplyMarkup = keyboard
+	b.api.Request(editMsg)
+	b.answerCallback(query.ID, "", false)
+}
+
+// historyList menyusun daftar permainan terakhir di sebuah grup.
+func (b *Bot) historyList(chatID int64, lang string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	games, err := b.db.GetRecentGames(chatID, historyLimit)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(games) == 0 {
+		return b.localizer.Get(lang, "history_empty"), nil, nil
+	}
+
+	var text strings.Builder
+	text.WriteString(b.localizer.Get(lang, "history_title"))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, g := range games {
+		date := g.EndedAt.Format("02/01 15:04")
+		entry := b.localizer.Get(lang, "history_entry")
+		entry = strings.Replace(entry, "{number}", strconv.Itoa(i+1), 1)
+		entry = strings.Replace(entry, "{date}", date, 1)
+		entry = strings.Replace(entry, "{rounds}", strconv.Itoa(g.RoundsPlayed), 1)
+		entry = strings.Replace(entry, "{mode}", b.localizer.Get(lang, "history_mode_"+g.Mode), 1)
+		text.WriteString(entry)
+
+		label := strings.Replace(b.localizer.Get(lang, "button_history_game"), "{number}", strconv.Itoa(i+1), 1)
+		label = strings.Replace(label, "{date}", date, 1)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "history_game_"+g.ID),
+		))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return text.String(), &keyboard, nil
+}
+
+// historyDetail menyusun skor akhir dan jalannya setiap ronde dari satu permainan.
+func (b *Bot) historyDetail(chatID int64, gameID, lang string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	detail, err := b.db.GetGameDetail(gameID)
+	if err != nil {
+		return "", nil, err
+	}
+	// Permainan grup lain tidak boleh dibuka lewat callback buatan sendiri.
+	if detail.Game.ChatID != chatID {
+		return "", nil, errGameNotInChat
+	}
+
+	names := make(map[int64]string, len(detail.Players))
+	for _, p := range detail.Players {
+		names[p.PlayerID] = html.EscapeString(p.FirstName)
+	}
+	nameOf := func(id int64) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		return strconv.FormatInt(id, 10)
+	}
+
+	var text strings.Builder
+	title := b.localizer.Get(lang, "history_detail_title")
+	title = strings.Replace(title, "{date}", detail.Game.EndedAt.Format("02/01/2006 15:04"), 1)
+	title = strings.Replace(title, "{rounds}", strconv.Itoa(detail.Game.RoundsPlayed), 1)
+	title = strings.Replace(title, "{total_rounds}", strconv.Itoa(detail.Game.TotalRounds), 1)
+	text.WriteString(title)
+
+	for _, p := range detail.Players {
+		entry := b.localizer.Get(lang, "end_of_round_scoreboard_entry")
+		name := names[p.PlayerID]
+		if p.Won {
+			name = "🏆 " + name
+		}
+		if p.LeftEarly {
+			name += " " + b.localizer.Get(lang, "history_left_early")
+		}
+		entry = strings.Replace(entry, "{player_name}", name, 1)
+		entry = strings.Replace(entry, "{points}", strconv.Itoa(p.Points), 1)
+		text.WriteString(entry)
+	}
+
+	wrongByRound := make(map[int]int)
+	for _, g := range detail.Guesses {
+		if g.Result != db.GuessCorrect {
+			wrongByRound[g.Round]++
+		}
+	}
+
+	text.WriteString(b.localizer.Get(lang, "history_rounds_title"))
+	for _, r := range detail.Rounds {
+		entry := b.localizer.Get(lang, "history_round_"+r.Outcome)
+		entry = strings.Replace(entry, "{round}", strconv.Itoa(r.Round), 1)
+		entry = strings.Replace(entry, "{giver}", nameOf(r.ClueGiverID), 1)
+		entry = strings.Replace(entry, "{word}", html.EscapeString(strings.ToUpper(r.Word)), 1)
+		entry = strings.Replace(entry, "{clue}", html.EscapeString(r.Clue), 1)
+		entry = strings.Replace(entry, "{wrong}", strconv.Itoa(wrongByRound[r.Round]), 1)
+		if r.WinnerID != nil {
+			entry = strings.Replace(entry, "{winner}", nameOf(*r.WinnerID), 1)
+		}
+		if r.GuessSeconds != nil {
+			entry = strings.Replace(entry, "{seconds}", strconv.FormatFloat(*r.GuessSeconds, 'f', 1, 64), 1)
+		}
+		text.WriteString(entry)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_history_back"), "history_list"),
+	))
+	return text.String(), &keyboard, nil
+}
+
+// historyProfileStats menyusun bagian riwayat permainan di profil pemain.
+func (b *Bot) historyProfileStats(playerID int64, lang string) string {
+	stats, err := b.db.GetPlayerHistoryStats(playerID)
+	if err != nil || stats.Games == 0 {
+		return ""
+	}
+
+	text := b.localizer.Get(lang, "profile_history_stats")
+	text = strings.Replace(text, "{games}", strconv.Itoa(stats.Games), 1)
+	text = strings.Replace(text, "{avg_points}", strconv.FormatFloat(float64(stats.TotalPoints)/float64(stats.Games), 'f', 1, 64), 1)
+	text = strings.Replace(text, "{best_points}", strconv.Itoa(stats.BestPoints), 1)
+	text = strings.Replace(text, "{clues_solved}", strconv.Itoa(stats.CluesSolved), 1)
+	text = strings.Replace(text, "{clues_given}", strconv.Itoa(stats.CluesGiven), 1)
+	text = strings.Replace(text, "{correct}", strconv.Itoa(stats.CorrectGuesses), 1)
+	text = strings.Replace(text, "{total_guesses}", strconv.Itoa(stats.CorrectGuesses+stats.WrongGuesses), 1)
+	text = strings.Replace(text, "{avg_seconds}", strconv.FormatFloat(stats.AvgGuessSeconds, 'f', 1, 64), 1)
+	return text
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const ledgerPageSize = 10
+
+// handleRiwayatCommand menampilkan riwayat perubahan poin pemain, hanya di chat pribadi.
+func (b *Bot) handleRiwayatCommand(message *tgbotapi.Message, player *db.Player) {
+	chatID := message.Chat.ID
+	lang := b.getUserLang(message.From)
+	if !message.Chat.IsPrivate() {
+		b.sendMessage(chatID, b.localizer.Get(lang, "private_chat_only"), false)
+		return
+	}
+
+	text, keyboard, err := b.ledgerPage(player.TelegramUserID, 0, lang)
+	if err != nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "ledger_fetch_fail"), false)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	b.api.Send(msg)
+}
+
+// handleLedgerCallback berpindah halaman riwayat poin (prefix: "riwayat_").
+func (b *Bot) handleLedgerCallback(query *tgbotapi.CallbackQuery) {
+	lang := b.getUserLang(query.From)
+	page, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "riwayat_"))
+
+	text, keyboard, err := b.ledgerPage(query.From.ID, page, lang)
+	if err != nil {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "ledger_fetch_fail"), true)
+		return
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	editMsg.ParseMode = tgbotapi.ModeHTML
+	editMsg.ReplyMarkup = keyboard
+	b.api.Request(editMsg)
+	b.answerCallback(query.ID, "", false)
+}
+
+// ledgerPage menyusun satu halaman riwayat poin beserta tombol navigasinya.
+func (b *Bot) ledgerPage(playerID int64, page int, lang string) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	totals, err := b.db.GetLedgerTotals(playerID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pages := (totals.Entries + ledgerPageSize - 1) / ledgerPageSize
+	if pages == 0 {
+		pages = 1
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+
+	title := b.localizer.Get(lang, "ledger_title")
+	title = strings.Replace(title, "{page}", strconv.Itoa(page+1), 1)
+	title = strings.Replace(title, "{pages}", strconv.Itoa(pages), 1)
+
+	var text strings.Builder
+	text.WriteString(title)
+	if totals.Entries == 0 {
+		text.WriteString(b.localizer.Get(lang, "ledger_empty"))
+		return text.String(), nil, nil
+	}
+
+	entries, err := b.db.GetPointsLedger(playerID, ledgerPageSize, page*ledgerPageSize)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, e := range entries {
+		entry := b.localizer.Get(lang, "ledger_entry")
+		entry = strings.Replace(entry, "{date}", e.CreatedAt.Format("02/01/2006 15:04"), 1)
+		entry = strings.Replace(entry, "{delta}", fmt.Sprintf("%+d", e.Delta), 1)
+		entry = strings.Replace(entry, "{reason}", html.EscapeString(b.ledgerReason(lang, e.Reason)), 1)
+		entry = strings.Replace(entry, "{balance}", strconv.Itoa(e.Balance), 1)
+		text.WriteString(entry)
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_prev_page"), fmt.Sprintf("riwayat_%d", page-1)))
+	}
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "button_next_page"), fmt.Sprintf("riwayat_%d", page+1)))
+	}
+	if len(row) == 0 {
+		return text.String(), nil, nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	return text.String(), &keyboard, nil
+}
+
+// ledgerReason menerjemahkan alasan perubahan saldo. Alasan yang belum punya
+// terjemahan ditampilkan apa adanya.
+func (b *Bot) ledgerReason(lang, reason string) string {
+	key := "ledger_reason_" + reason
+	if text := b.localizer.Get(lang, key); text != key {
+		return text
+	}
+	return reason
+}
+
+// handleLedgerAudit mencocokkan saldo seorang pemain dengan jumlah ledger-nya (khusus admin).
+// Contoh: /ceksaldo 123456789
+func (b *Bot) handleLedgerAudit(message *tgbotapi.Message) {
+	lang := "id"
+	chatID := message.Chat.ID
+
+	playerID, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "ledger_audit_usage"), true)
+		return
+	}
+
+	player, err := b.db.GetPlayerByID(playerID)
+	if err != nil || player == nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "ledger_audit_not_found"), false)
+		return
+	}
+	totals, err := b.db.GetLedgerTotals(playerID)
+	if err != nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "ledger_fetch_fail"), false)
+		return
+	}
+
+	sum := totals.Sum
+	key := "ledger_audit_ok"
+	if sum != player.Points {
+		key = "ledger_audit_mismatch"
+		log.Printf("Ledger mismatch for player %d: balance %d, ledger sum %d", playerID, player.Points, sum)
+	}
+	text := b.localizer.Get(lang, key)
+	text = strings.Replace(text, "{name}", html.EscapeString(player.FirstName), 1)
+	text = strings.Replace(text, "{id}", strconv.FormatInt(playerID, 10), 1)
+	text = strings.Replace(text, "{balance}", strconv.Itoa(player.Points), 1)
+	text = strings.Replace(text, "{sum}", strconv.Itoa(sum), 1)
+	text = strings.Replace(text, "{count}", strconv.Itoa(totals.Entries), 1)
+	text = strings.Replace(text, "{diff}", fmt.Sprintf("%+d", player.Points-sum), 1)
+	b.sendMessage(chatID, text, true)
+}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"html"
 	"strings"
@@ -27,16 +28,15 @@ func (b *Bot) handleProfileCommand(message *tgbotapi.Message) {
 	// 1. Siapkan Tampilan Lencana Utama
 	var mainBadgeDisplay string
 	if player.EquippedBadgeID != nil {
-		badge, err := b.db.GetBadgeByID(*player.EquippedBadgeID)
-		if err == nil {
+		if badge, ok := b.badgeCatalog()[*player.EquippedBadgeID]; ok {
 			mainBadgeDisplay = badge.Emoji + " "
 		}
 	}
 
 	// 2. Hitung Win Rate
 	winRate := 0.0
-	if player.GamesPlayed > 0 {
-		winRate = (float64(player.GamesWon) / float64(player.GamesPlayed)) * 100
+	if player.GamesCounted > 0 {
+		winRate = (float64(player.GamesWon) / float64(player.GamesCounted)) * 100
 	}
 
 	// 3. Siapkan Tampilan Statistik Peran (Clue Giver Success Rate)
@@ -68,23 +68,27 @@ func (b *Bot) handleProfileCommand(message *tgbotapi.Message) {
 	profileText := fmt.Sprintf(
 		"--- 👤 PROFIL PEMAIN ---\n"+
 		"<b>Nama:</b> %s%s\n"+
-		"<b>Poin:</b> %d\n\n"+
+		"<b>Poin:</b> %d\n"+
+		"%s\n"+
 		"--- 📊 STATISTIK ---\n"+
 		"• Main: %d | Menang: %d (%.0f%% Win Rate)\n"+
 		"• Total Tebakan: %d kata\n"+
 		"• Tebakan Tercepat: %s\n"+
 		"• Sukses Beri Petunjuk: %.0f%%\n\n"+
+		"%s"+
 		"--- 🎖️ KOLEKSI LENCANA ---\n"+
 		"%s",
 		mainBadgeDisplay,
 		html.EscapeString(player.FirstName),
 		player.Points,
+		b.profileRating(player, lang),
 		player.GamesPlayed,
 		player.GamesWon,
 		winRate,
 		player.WordsGuessedCount,
 		fastestGuessDisplay,
 		clueSuccessRate,
+		b.historyProfileStats(player.TelegramUserID, lang),
 		allBadgesDisplay,
 	)
 
@@ -96,6 +100,7 @@ func (b *Bot) handleProfileCommand(message *tgbotapi.Message) {
 			tgbotapi.NewInlineKeyboardButtonData("🏆 Papan Peringkat", "profile_action_leaderboard"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎯 Pencapaian", "profile_action_achievements"),
 			tgbotapi.NewInlineKeyboardButtonData("🔄 Segarkan", "profile_action_refresh"),
 		),
 	)
@@ -189,25 +194,25 @@ func (b *Bot) purchaseBadge(message *tgbotapi.Message, player *db.Player, badgeI
 		}
 	}
 
-	// 3. Periksa apakah poin pemain mencukupi
-	if player.Points < badgeToBuy.CriteriaValue {
+	// 3. Kurangi poin jika mencukupi. Pemeriksaan dan pengurangan terjadi sekaligus di database.
+	newPoints, err := b.db.SpendPoints(player.TelegramUserID, badgeToBuy.CriteriaValue, db.LedgerPurchase, strconv.Itoa(badgeID))
+	if errors.Is(err, db.ErrInsufficientPoints) {
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("Poin Anda tidak cukup! Butuh %d Poin, Anda hanya punya %d Poin.", badgeToBuy.CriteriaValue, player.Points), false)
 		return
 	}
-
-	// 4. Proses transaksi: kurangi poin dan berikan lencana
-	err = b.db.AddPoints(player.TelegramUserID, -badgeToBuy.CriteriaValue)
 	if err != nil {
 		log.Printf("Failed to subtract points for badge purchase: %v", err)
 		b.sendMessage(message.Chat.ID, "Terjadi kesalahan saat transaksi, coba lagi nanti.", false)
 		return
 	}
 
+	// 4. Berikan lencana
 	err = b.db.AwardBadgeToPlayer(player.TelegramUserID, badgeID)
+	b.invalidatePlayerBadges(player.TelegramUserID)
 	if err != nil {
 		log.Printf("Failed to award badge after purchase: %v", err)
 		// Kembalikan poin jika gagal memberikan lencana
-		b.db.AddPoints(player.TelegramUserID, badgeToBuy.CriteriaValue)
+		b.db.AddPoints(player.TelegramUserID, badgeToBuy.CriteriaValue, db.LedgerRefund, strconv.Itoa(badgeID))
 		b.sendMessage(message.Chat.ID, "Terjadi kesalahan saat memberikan lencana, poin Anda telah dikembalikan.", false)
 		return
 	}
@@ -216,7 +221,8 @@ func (b *Bot) purchaseBadge(message *tgbotapi.Message, player *db.Player, badgeI
 	successMsg := fmt.Sprintf("✅ Pembelian Berhasil!\n\nAnda telah membeli lencana %s %s. Poin Anda sekarang %d.",
 		badgeToBuy.Emoji,
 		badgeToBuy.Name,
-		player.Points-badgeToBuy.CriteriaValue,
+		newPoints,
 	)
 	b.sendMessage(message.Chat.ID, successMsg, true)
+	b.checkAchievements(player.TelegramUserID, message.Chat.ID, achievementEvent{})
 }
@@ -0,0 +1,264 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pointTierPresets adalah pilihan skema poin yang bisa dipilih lewat tombol di /settings.
+var pointTierPresets = [][]int{
+	{20, 15, 10, 5},
+	{25, 20, 15, 10, 5},
+	{30, 20, 10},
+	{10, 10, 10, 10},
+}
+
+// handleSettingsCommand menampilkan pengaturan permainan grup beserta tombol untuk mengubahnya.
+func (b *Bot) handleSettingsCommand(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	lang := b.getUserLang(message.From)
+	if !message.Chat.IsGroup() && !message.Chat.IsSuperGroup() {
+		b.sendMessage(chatID, b.localizer.Get(lang, "group_command_only"), false)
+		return
+	}
+	if !b.isGroupAdmin(chatID, message.From.ID) {
+		b.sendMessage(chatID, b.localizer.Get(lang, "settings_admin_only"), false)
+		return
+	}
+
+	settings, err := b.db.GetChatSettings(chatID)
+	if err != nil {
+		b.sendMessage(chatID, b.localizer.Get(lang, "settings_load_error"), false)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, b.settingsText(lang, settings))
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = b.settingsKeyboard(lang)
+	b.api.Send(msg)
+}
+
+// handleSettingsCallback mengubah satu pengaturan sesuai tombol yang ditekan.
+func (b *Bot) handleSettingsCallback(query *tgbotapi.CallbackQuery) {
+	chatID := query.Message.Chat.ID
+	lang := b.getUserLang(query.From)
+
+	if !b.isGroupAdmin(chatID, query.From.ID) {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "settings_admin_only"), true)
+		return
+	}
+
+	settings, err := b.db.GetChatSettings(chatID)
+	if err != nil {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "settings_load_error"), true)
+		return
+	}
+
+	switch strings.TrimPrefix(query.Data, "settings_") {
+	case "guess_dec":
+		settings.GuessSeconds = clamp(settings.GuessSeconds-10, 20, 180)
+	case "guess_inc":
+		settings.GuessSeconds = clamp(settings.GuessSeconds+10, 20, 180)
+	case "warning_dec":
+		settings.WarningSeconds = clamp(settings.WarningSeconds-5, 0, settings.GuessSeconds-5)
+	case "warning_inc":
+		settings.WarningSeconds = clamp(settings.WarningSeconds+5, 0, settings.GuessSeconds-5)
+	case "reminder_dec":
+		settings.ClueReminderSeconds = clamp(settings.ClueReminderSeconds-15, 30, 300)
+	case "reminder_inc":
+		settings.ClueReminderSeconds = clamp(settings.ClueReminderSeconds+15, 30, 300)
+	case "timeout_dec":
+		settings.ClueTimeoutSeconds = clamp(settings.ClueTimeoutSeconds-30, 60, 600)
+	case "timeout_inc":
+		settings.ClueTimeoutSeconds = clamp(settings.ClueTimeoutSeconds+30, 60, 600)
+	case "missed_dec":
+		settings.MaxMissedTurns = clamp(settings.MaxMissedTurns-1, 1, 5)
+	case "missed_inc":
+		settings.MaxMissedTurns = clamp(settings.MaxMissedTurns+1, 1, 5)
+	case "rounds_dec":
+		settings.DefaultRounds = clamp(settings.DefaultRounds-1, settings.MinRounds, settings.MaxRounds)
+	case "rounds_inc":
+		settings.DefaultRounds = clamp(settings.DefaultRounds+1, settings.MinRounds, settings.MaxRounds)
+	case "maxrounds_dec":
+		settings.MaxRounds = clamp(settings.MaxRounds-5, settings.MinRounds, 50)
+	case "maxrounds_inc":
+		settings.MaxRounds = clamp(settings.MaxRounds+5, settings.MinRounds, 50)
+	case "norepeat_dec":
+		settings.NoRepeatWindow = clamp(settings.NoRepeatWindow-10, 0, 200)
+	case "norepeat_inc":
+		settings.NoRepeatWindow = clamp(settings.NoRepeatWindow+10, 0, 200)
+	case "rerolls_dec":
+		settings.MaxRerolls = clamp(settings.MaxRerolls-1, 0, 5)
+	case "rerolls_inc":
+		settings.MaxRerolls = clamp(settings.MaxRerolls+1, 0, 5)
+	case "players_dec":
+		settings.MaxPlayers = stepMaxPlayers(settings.MaxPlayers, -1)
+	case "players_inc":
+		settings.MaxPlayers = stepMaxPlayers(settings.MaxPlayers, 1)
+	case "autostart_dec":
+		settings.AutoStartSeconds = clamp(settings.AutoStartSeconds-15, 0, 300)
+	case "autostart_inc":
+		settings.AutoStartSeconds = clamp(settings.AutoStartSeconds+15, 0, 300)
+	case "minwin_dec":
+		settings.MinPlayersForWin = clamp(settings.MinPlayersForWin-1, 2, 10)
+	case "minwin_inc":
+		settings.MinPlayersForWin = clamp(settings.MinPlayersForWin+1, 2, 10)
+	case "expiry_dec":
+		settings.LobbyExpirySeconds = clamp(settings.LobbyExpirySeconds-60, 120, 3600)
+	case "expiry_inc":
+		settings.LobbyExpirySeconds = clamp(settings.LobbyExpirySeconds+60, 120, 3600)
+	case "tiers":
+		settings.PointTiers = nextPointTiers(settings.PointTiers)
+	case "reset":
+		settings = db.DefaultChatSettings(chatID)
+	}
+	// Peringatan dan ronde bawaan harus tetap berada di dalam batas yang baru.
+	settings.WarningSeconds = clamp(settings.WarningSeconds, 0, settings.GuessSeconds-5)
+	settings.ClueReminderSeconds = clamp(settings.ClueReminderSeconds, 30, settings.ClueTimeoutSeconds-15)
+	settings.DefaultRounds = clamp(settings.DefaultRounds, settings.MinRounds, settings.MaxRounds)
+
+	if err := b.db.SaveChatSettings(settings); err != nil {
+		b.answerCallback(query.ID, b.localizer.Get(lang, "settings_save_error"), true)
+		return
+	}
+
+	keyboard := b.settingsKeyboard(lang)
+	editMsg := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, b.settingsText(lang, settings))
+	editMsg.ParseMode = tgbotapi.ModeHTML
+	editMsg.ReplyMarkup = &keyboard
+	b.api.Request(editMsg)
+	b.answerCallback(query.ID, b.localizer.Get(lang, "settings_saved"), false)
+}
+
+func (b *Bot) settingsText(lang string, settings *db.ChatSettings) string {
+	tiers := make([]string, len(settings.PointTiers))
+	for i, t := range settings.PointTiers {
+		tiers[i] = strconv.Itoa(t)
+	}
+
+	text := b.localizer.Get(lang, "settings_view")
+	text = strings.Replace(text, "{guess_seconds}", strconv.Itoa(settings.GuessSeconds), 1)
+	text = strings.Replace(text, "{warning_seconds}", strconv.Itoa(settings.WarningSeconds), 1)
+	text = strings.Replace(text, "{reminder_seconds}", strconv.Itoa(settings.ClueReminderSeconds), 1)
+	text = strings.Replace(text, "{timeout_seconds}", strconv.Itoa(settings.ClueTimeoutSeconds), 1)
+	text = strings.Replace(text, "{max_missed_turns}", strconv.Itoa(settings.MaxMissedTurns), 1)
+	text = strings.Replace(text, "{default_rounds}", strconv.Itoa(settings.DefaultRounds), 1)
+	text = strings.Replace(text, "{min_rounds}", strconv.Itoa(settings.MinRounds), 1)
+	text = strings.Replace(text, "{max_rounds}", strconv.Itoa(settings.MaxRounds), 1)
+	text = strings.Replace(text, "{point_tiers}", strings.Join(tiers, " / "), 1)
+	text = strings.Replace(text, "{no_repeat_window}", strconv.Itoa(settings.NoRepeatWindow), 1)
+	text = strings.Replace(text, "{max_rerolls}", strconv.Itoa(settings.MaxRerolls), 1)
+	maxPlayers := b.localizer.Get(lang, "settings_unlimited")
+	if settings.MaxPlayers > 0 {
+		maxPlayers = strconv.Itoa(settings.MaxPlayers)
+	}
+	text = strings.Replace(text, "{max_players}", maxPlayers, 1)
+	text = strings.Replace(text, "{auto_start_seconds}", strconv.Itoa(settings.AutoStartSeconds), 1)
+	text = strings.Replace(text, "{lobby_expiry_minutes}", strconv.Itoa(settings.LobbyExpirySeconds/60), 1)
+	text = strings.Replace(text, "{min_players_for_win}", strconv.Itoa(settings.MinPlayersForWin), 1)
+	return text
+}
+
+func (b *Bot) settingsKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	row := func(label, key string) []tgbotapi.InlineKeyboardButton {
+		name := b.localizer.Get(lang, label)
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("➖ %s", name), "settings_"+key+"_dec"),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("➕ %s", name), "settings_"+key+"_inc"),
+		)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(
+		row("settings_button_guess", "guess"),
+		row("settings_button_warning", "warning"),
+		row("settings_button_reminder", "reminder"),
+		row("settings_button_timeout", "timeout"),
+		row("settings_button_missed", "missed"),
+		row("settings_button_rounds", "rounds"),
+		row("settings_button_max_rounds", "maxrounds"),
+		row("settings_button_no_repeat", "norepeat"),
+		row("settings_button_rerolls", "rerolls"),
+		row("settings_button_max_players", "players"),
+		row("settings_button_auto_start", "autostart"),
+		row("settings_button_lobby_expiry", "expiry"),
+		row("settings_button_min_win", "minwin"),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "settings_button_tiers"), "settings_tiers"),
+			tgbotapi.NewInlineKeyboardButtonData(b.localizer.Get(lang, "settings_button_reset"), "settings_reset"),
+		),
+	)
+}
+
+// isGroupAdmin memeriksa apakah seorang pengguna adalah admin atau pemilik grup.
+func (b *Bot) isGroupAdmin(chatID int64, userID int64) bool {
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: chatID,
+			UserID: userID,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to check admin status of %d in chat %d: %v", userID, chatID, err)
+		return false
+	}
+	return member.Status == "creator" || member.Status == "administrator"
+}
+
+// nextPointTiers memilih skema poin berikutnya dari pointTierPresets.
+func nextPointTiers(current []int) []int {
+	for i, preset := range pointTierPresets {
+		if equalInts(preset, current) {
+			return pointTierPresets[(i+1)%len(pointTierPresets)]
+		}
+	}
+	return pointTierPresets[0]
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func clamp(value, lo, hi int) int {
+	if value > hi {
+		value = hi
+	}
+	if value < lo {
+		value = lo
+	}
+	return value
+}
+
+// Batas pemain yang bisa dipilih di /settings. 0 berarti tanpa batas dan
+// letaknya setelah maxPlayersCap: menaikkan batas dari 30 menghapus batasnya,
+// menurunkan dari tanpa batas kembali ke 30.
+const (
+	minPlayersCap = 2
+	maxPlayersCap = 30
+)
+
+func stepMaxPlayers(current, delta int) int {
+	if current <= 0 {
+		if delta < 0 {
+			return maxPlayersCap
+		}
+		return 0
+	}
+	next := current + delta
+	if next > maxPlayersCap {
+		return 0
+	}
+	return clamp(next, minPlayersCap, maxPlayersCap)
+}
@@ -6,7 +6,6 @@ import (
 
 	"detektif-kata-bot/internal/config"
 	"detektif-kata-bot/internal/db"
-	"detektif-kata-bot/internal/game"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -30,7 +29,7 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		message = update.CallbackQuery.Message
 	}
 
-	go func() {
+	b.background(func() {
 		// TANDA: Logika perbaikan dimulai di sini
 		chatTypeToSave := chat.Type
 		if chat.IsSuperGroup() {
@@ -42,7 +41,7 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		if err != nil {
 			log.Printf("Failed to save chat info for chat ID %d: %v", chat.ID, err)
 		}
-	}()
+	})
 
 	isMember, err := b.checkUserIsMember(from)
 	if err != nil {
@@ -73,6 +72,11 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		return
 	}
 
+	// Pesan pribadi berarti pengguna sudah memulai bot, jadi bot bisa mengirim PM kepadanya.
+	if chat.IsPrivate() {
+		b.markPMReachable(from.ID, true)
+	}
+
 	if message.IsCommand() {
 		b.handleCommand(message, player)
 	} else if chat.IsPrivate() {
@@ -85,25 +89,20 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 func (b *Bot) handlePrivateMessage(message *tgbotapi.Message, player *db.Player) {
 	lang := b.getUserLang(message.From)
 
-	b.mu.RLock()
-	gameStatesCopy := make(map[int64]*game.GameState)
-	for k, v := range b.gameStates {
-		gameStatesCopy[k] = v
+	if b.routeClue(message, player, lang) {
+		return
 	}
-	soloState, soloOk := b.soloGameStates[player.TelegramUserID]
-	b.mu.RUnlock()
 
-	for chatID, state := range gameStatesCopy {
-		if state.IsActive && state.Status == "waiting_for_clue" && state.ClueGiver != nil && state.ClueGiver.TelegramUserID == player.TelegramUserID {
-			b.handleClueSubmission(message, player, state, chatID, lang)
-			return
-		}
-	}
+	// Permainan solo juga diproses lewat mailbox, dengan ID pemain sebagai kuncinya.
+	b.run(player.TelegramUserID, func() {
+		b.mu.RLock()
+		soloState, soloOk := b.soloGameStates[player.TelegramUserID]
+		b.mu.RUnlock()
 
-	if soloOk && soloState.IsActive {
-		b.handleSoloGuess(message, player, soloState, lang)
-		return
-	}
+		if soloOk && soloState.IsActive {
+			b.handleSoloGuess(message, player, soloState, lang)
+		}
+	})
 }
 
 func (b *Bot) checkUserIsMember(user *tgbotapi.User) (bool, error) {
@@ -140,6 +139,9 @@ func (b *Bot) sendMessage(chatID int64, text string, useHTML bool) error {
 	_, err := b.api.Send(msg)
 	if err != nil {
 		log.Printf("Failed to send message to chat %d: %v", chatID, err)
+		if chatID > 0 && isForbidden(err) {
+			b.markPMReachable(chatID, false)
+		}
 	}
 	return err
 }
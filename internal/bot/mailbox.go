@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"detektif-kata-bot/internal/game"
+)
+
+// mailboxIdle adalah berapa lama mailbox kosong dibiarkan sebelum goroutine-nya berhenti.
+const mailboxIdle = 5 * time.Minute
+
+// mailbox menjalankan semua pekerjaan untuk satu chat secara berurutan, jadi
+// event pemain dan timer tidak pernah memproses permainan yang sama bersamaan.
+type mailbox struct {
+	jobs    chan func()
+	pending int // dijaga oleh mailboxSet.mu
+}
+
+// run menjalankan fn di mailbox chat dan menunggu sampai selesai.
+// Jangan dipanggil dari dalam pekerjaan mailbox chat yang sama.
+func (b *Bot) run(chatID int64, fn func()) {
+	done := make(chan struct{})
+	b.enqueue(chatID, func() {
+		defer close(done)
+		fn()
+	})
+	<-done
+}
+
+// dispatch mengirim event ke engine chat lewat mailbox-nya dan menunggu hasilnya.
+func (b *Bot) dispatch(chatID int64, ev game.Event) error {
+	var err error
+	b.run(chatID, func() {
+		err = b.process(chatID, ev)
+	})
+	return err
+}
+
+// post mengirim event tanpa menunggu. Dipakai oleh timer; jangan dipanggil
+// dari dalam pekerjaan mailbox, karena antreannya bisa penuh dan pekerjaan
+// itu tidak akan pernah selesai.
+func (b *Bot) post(chatID int64, ev game.Event) {
+	b.enqueue(chatID, func() {
+		b.process(chatID, ev)
+	})
+}
+
+func (b *Bot) enqueue(chatID int64, job func()) {
+	b.mailboxes.enqueue(chatID, job)
+}
+
+// background menjalankan job di goroutine sendiri, misalnya penyimpanan
+// statistik yang tidak perlu ditunggu. Stop menunggu semuanya selesai.
+func (b *Bot) background(job func()) {
+	b.backgroundJobs.Add(1)
+	go func() {
+		defer b.backgroundJobs.Done()
+		job()
+	}()
+}
+
+// mailboxSet menyimpan satu mailbox per kunci, biasanya ID chat. Update
+// Telegram dan permainan memakai set terpisah, karena pekerjaan update
+// menunggu pekerjaan permainan di chat yang sama.
+type mailboxSet struct {
+	mu    sync.Mutex
+	boxes map[int64]*mailbox
+	busy  sync.WaitGroup
+}
+
+func newMailboxSet() *mailboxSet {
+	return &mailboxSet{boxes: make(map[int64]*mailbox)}
+}
+
+// enqueue menaruh pekerjaan di akhir antrean kunci tanpa menunggu hasilnya.
+func (s *mailboxSet) enqueue(chatID int64, job func()) {
+	s.mu.Lock()
+	box, ok := s.boxes[chatID]
+	if !ok {
+		box = &mailbox{jobs: make(chan func(), 64)}
+		s.boxes[chatID] = box
+		go s.drain(chatID, box)
+	}
+	// pending dinaikkan sebelum mengirim supaya mailbox tidak dihentikan
+	// selagi masih ada pekerjaan yang akan masuk.
+	box.pending++
+	s.busy.Add(1)
+	s.mu.Unlock()
+
+	box.jobs <- job
+}
+
+// wait menunggu sampai semua pekerjaan yang sudah masuk selesai.
+func (s *mailboxSet) wait() {
+	s.busy.Wait()
+}
+
+// drain memproses pekerjaan satu per satu dan berhenti jika lama tidak ada pekerjaan.
+func (s *mailboxSet) drain(chatID int64, box *mailbox) {
+	idle := time.NewTimer(mailboxIdle)
+	defer idle.Stop()
+	for {
+		select {
+		case job := <-box.jobs:
+			job()
+			s.mu.Lock()
+			box.pending--
+			s.mu.Unlock()
+			s.busy.Done()
+
+			if !idle.Stop() {
+				<-idle.C
+			}
+			idle.Reset(mailboxIdle)
+		case <-idle.C:
+			s.mu.Lock()
+			if box.pending > 0 {
+				s.mu.Unlock()
+				idle.Reset(mailboxIdle)
+				continue
+			}
+			delete(s.boxes, chatID)
+			s.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package bot_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/game"
+	"detektif-kata-bot/internal/telegramfake"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// startRound membuka lobi dengan tiga pemain dan menunggu ronde pertama.
+// Yang dikembalikan adalah Pemberi Petunjuk ronde itu.
+func startRound(t *testing.T, b interface{ HandleUpdate(tgbotapi.Update) }, client *telegramfake.Client, prompt string) tgbotapi.User {
+	t.Helper()
+	b.HandleUpdate(telegramfake.TextUpdate(group, ani, 1, "/startgame"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, budi, 2, "/join"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, citra, 3, "/join"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, ani, 4, "/play"))
+
+	var giver tgbotapi.User
+	waitFor(t, 5*time.Second, "the secret word prompt", func() bool {
+		for _, u := range []tgbotapi.User{ani, budi, citra} {
+			if countSent(client, u.ID, prompt) > 0 {
+				giver = u
+				return true
+			}
+		}
+		return false
+	})
+	return giver
+}
+
+func TestGuessesRaceWithGuessTimer(t *testing.T) {
+	b, client, l := newTestBot(t)
+	giver := startRound(t, b, client, phrase(l, "secret_word_prompt"))
+
+	b.HandleUpdate(telegramfake.TextUpdate(private(giver), giver, 10, "meong"))
+	clue, ok := client.FindSent(groupID, "MEONG")
+	if !ok {
+		t.Fatal("clue was not announced in the group")
+	}
+
+	// Semua penebak dan timer tebakan berebut mailbox yang sama.
+	var wg sync.WaitGroup
+	for _, u := range []tgbotapi.User{ani, budi, citra} {
+		if u.ID == giver.ID {
+			continue
+		}
+		u := u
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, text := range []string{"anjing", "kelinci", "kucing"} {
+				b.HandleUpdate(telegramfake.ReplyUpdate(group, u, int(u.ID)*100+i, text, clue.MessageID))
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.Post(groupID, game.TimeoutEvent{Timer: game.TimerGuessWarning, Round: 1})
+		b.Post(groupID, game.TimeoutEvent{Timer: game.TimerGuess, Round: 1})
+	}()
+	wg.Wait()
+	b.Flush(groupID)
+
+	won := countSent(client, groupID, phrase(l, "round_won_announcement"))
+	timesUp := countSent(client, groupID, phrase(l, "times_up"))
+	if won+timesUp != 1 {
+		t.Fatalf("round ended %d times (won %d, time up %d), want once", won+timesUp, won, timesUp)
+	}
+}
+
+func TestFollowUpEventRunsInsideMailbox(t *testing.T) {
+	b, client, l := newTestBot(t)
+	for _, u := range []tgbotapi.User{ani, budi, citra} {
+		client.FailFor(u.ID, errors.New("Forbidden: bot was blocked by the user"))
+	}
+
+	b.HandleUpdate(telegramfake.TextUpdate(group, ani, 1, "/startgame"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, budi, 2, "/join"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, citra, 3, "/join"))
+	b.HandleUpdate(telegramfake.TextUpdate(group, ani, 4, "/play"))
+
+	// Ronde dimulai dari timer, lalu efek RoundStarted gagal mengirim PM dan
+	// memicu SkipTurnEvent dari dalam pekerjaan mailbox yang sama.
+	b.Post(groupID, game.TimeoutEvent{Timer: game.TimerNextRound, Round: 0})
+	done := make(chan struct{})
+	go func() {
+		b.Flush(groupID)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("mailbox is stuck after a follow-up event")
+	}
+
+	if countSent(client, groupID, phrase(l, "pm_failed_turn_skipped")) == 0 {
+		t.Fatal("turn was not skipped after the secret word could not be sent")
+	}
+}
+
+func TestClueAnnouncementFailureEndsGame(t *testing.T) {
+	b, client, l := newTestBot(t)
+	giver := startRound(t, b, client, phrase(l, "secret_word_prompt"))
+
+	client.FailFor(groupID, errors.New("Bad Request: not enough rights to send text messages"))
+	b.HandleUpdate(telegramfake.TextUpdate(private(giver), giver, 10, "meong"))
+	b.Flush(groupID)
+	client.FailFor(groupID, nil)
+
+	// Permainan sudah diakhiri lewat engine, jadi grup bisa membuka lobi baru.
+	b.HandleUpdate(telegramfake.TextUpdate(group, ani, 20, "/startgame"))
+	b.Flush(groupID)
+	if countSent(client, groupID, phrase(l, "lobby_opened")) != 2 {
+		t.Fatal("game is still running after the clue could not be announced")
+	}
+}
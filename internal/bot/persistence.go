@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"log"
+
+	"detektif-kata-bot/internal/db"
+	"detektif-kata-bot/internal/game"
+)
+
+// saveGame menyimpan snapshot permainan grup supaya bisa dilanjutkan setelah restart.
+func (b *Bot) saveGame(chatID int64) {
+	b.mu.RLock()
+	engine, ok := b.games[chatID]
+	if !ok || !engine.State.IsActive {
+		b.mu.RUnlock()
+		return
+	}
+	data, err := engine.State.Snapshot()
+	b.mu.RUnlock()
+	if err != nil {
+		log.Printf("Failed to snapshot game in chat %d: %v", chatID, err)
+		return
+	}
+	b.db.SaveGameSnapshot(db.SnapshotGroup, chatID, data)
+}
+
+// saveSoloGame menyimpan snapshot permainan solo milik seorang pemain.
+func (b *Bot) saveSoloGame(userID int64) {
+	b.mu.RLock()
+	state, ok := b.soloGameStates[userID]
+	if !ok {
+		b.mu.RUnlock()
+		return
+	}
+	data, err := state.Snapshot()
+	b.mu.RUnlock()
+	if err != nil {
+		log.Printf("Failed to snapshot solo game of player %d: %v", userID, err)
+		return
+	}
+	b.db.SaveGameSnapshot(db.SnapshotSolo, userID, data)
+}
+
+// restoreGames memuat ulang semua permainan yang tersimpan, memasang kembali
+// timernya, lalu memberi tahu setiap chat bahwa permainannya dilanjutkan.
+func (b *Bot) restoreGames() {
+	snapshots, err := b.db.GetGameSnapshots()
+	if err != nil {
+		log.Printf("Failed to load game snapshots: %v", err)
+		return
+	}
+
+	lang := "id"
+	for _, snapshot := range snapshots {
+		switch snapshot.Kind {
+		case db.SnapshotGroup:
+			state, err := game.RestoreGame(snapshot.State)
+			if err != nil || !state.IsActive {
+				log.Printf("Discarding game snapshot for chat %d: %v", snapshot.ChatID, err)
+				b.db.DeleteGameSnapshot(db.SnapshotGroup, snapshot.ChatID)
+				continue
+			}
+
+			engine := b.newEngine(state)
+			b.mu.Lock()
+			b.games[snapshot.ChatID] = engine
+			b.mu.Unlock()
+
+			b.sendMessage(snapshot.ChatID, b.localizer.Get(lang, "game_resumed"), true)
+			for _, t := range engine.PendingTimers() {
+				b.armTimer(snapshot.ChatID, t)
+			}
+			log.Printf("Restored game in chat %d (status %s, round %d).", snapshot.ChatID, state.Status, state.Round)
+
+		case db.SnapshotSolo:
+			state, err := game.RestoreSoloGame(snapshot.State)
+			if err != nil || !state.IsActive {
+				log.Printf("Discarding solo snapshot for player %d: %v", snapshot.ChatID, err)
+				b.db.DeleteGameSnapshot(db.SnapshotSolo, snapshot.ChatID)
+				continue
+			}
+
+			b.mu.Lock()
+			b.soloGameStates[snapshot.ChatID] = state
+			b.mu.Unlock()
+
+			b.sendMessage(snapshot.ChatID, b.localizer.Get(lang, "solo_game_resumed"), true)
+			log.Printf("Restored solo game of player %d.", snapshot.ChatID)
+		}
+	}
+}
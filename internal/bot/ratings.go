@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+	"detektif-kata-bot/internal/game"
+	"detektif-kata-bot/internal/rating"
+)
+
+// updateRatings menghitung dan menyimpan rating baru semua pemain sebuah
+// permainan. Rating dibaca ulang dari database karena data pemain di
+// permainan bisa sudah usang, dan ratingMu mencegah dua permainan yang
+// selesai bersamaan saling menimpa rating pemain yang sama. Lencana rating
+// diperiksa setelah rating baru tersimpan.
+func (b *Bot) updateRatings(chatID int64, standings []game.Standing) {
+	b.ratingMu.Lock()
+	defer b.ratingMu.Unlock()
+
+	ids := make([]int64, len(standings))
+	for i, st := range standings {
+		ids[i] = st.Player.TelegramUserID
+	}
+	players, err := b.db.GetPlayersByIDs(ids)
+	if err != nil {
+		log.Printf("Failed to load players for rating update: %v", err)
+		return
+	}
+	current := make(map[int64]rating.Rating, len(players))
+	for _, p := range players {
+		current[p.TelegramUserID] = p.SkillRating()
+	}
+
+	results := make([]rating.Result, 0, len(standings))
+	for _, st := range standings {
+		r, ok := current[st.Player.TelegramUserID]
+		if !ok {
+			r = rating.New()
+		}
+		results = append(results, rating.Result{PlayerID: st.Player.TelegramUserID, Placement: st.Placement, Rating: r})
+	}
+
+	updated := rating.Update(results, time.Now())
+	updates := make([]db.RatingUpdate, 0, len(updated))
+	for _, res := range results {
+		updates = append(updates, db.RatingUpdate{PlayerID: res.PlayerID, Rating: updated[res.PlayerID]})
+	}
+	if err := b.db.UpdatePlayerRatings(updates); err != nil {
+		log.Printf("Failed to save rating update: %v", err)
+		return
+	}
+	for _, u := range updates {
+		b.checkAchievements(u.PlayerID, chatID, achievementEvent{})
+	}
+}
+
+// profileRating menampilkan rating pemain di profil. Deviasi ikut dinaikkan
+// sesuai lama pemain tidak bermain.
+func (b *Bot) profileRating(player *db.Player, lang string) string {
+	key := "profile_rating"
+	if player.RatedGames == 0 {
+		key = "profile_rating_unrated"
+	}
+	text := b.localizer.Get(lang, key)
+	text = strings.Replace(text, "{rating}", formatRating(player.SkillRating().Decayed(time.Now())), 1)
+	return strings.Replace(text, "{rated_games}", strconv.Itoa(player.RatedGames), 1)
+}
+
+// formatRating menampilkan rating beserta deviasinya, misalnya "1623 ±87".
+func formatRating(r rating.Rating) string {
+	return fmt.Sprintf("%.0f ±%.0f", r.Value, r.Deviation)
+}
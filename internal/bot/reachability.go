@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+	"detektif-kata-bot/internal/game"
+)
+
+// startPayloadJoin adalah awalan payload deep link /start dari tombol lobi.
+const startPayloadJoin = "join_"
+
+// canReachPrivately memeriksa apakah pemain sudah pernah memulai chat pribadi
+// dengan bot, memakai cache di memori sebelum data dari database.
+func (b *Bot) canReachPrivately(p *db.Player) bool {
+	b.pmMu.Lock()
+	defer b.pmMu.Unlock()
+	if reachable, ok := b.pmReachable[p.TelegramUserID]; ok {
+		return reachable
+	}
+	b.pmReachable[p.TelegramUserID] = p.CanReceivePM
+	return p.CanReceivePM
+}
+
+// markPMReachable memperbarui cache dan database jika status berubah. Jika
+// penyimpanan gagal, entri cache dibuang supaya pesan berikutnya mencoba lagi.
+func (b *Bot) markPMReachable(userID int64, reachable bool) {
+	b.pmMu.Lock()
+	prev, known := b.pmReachable[userID]
+	b.pmReachable[userID] = reachable
+	b.pmMu.Unlock()
+
+	if known && prev == reachable {
+		return
+	}
+	b.background(func() {
+		if err := b.db.SetPlayerPMReachable(userID, reachable); err != nil {
+			log.Printf("Failed to save PM reachability of player %d: %v", userID, err)
+			b.pmMu.Lock()
+			if b.pmReachable[userID] == reachable {
+				delete(b.pmReachable, userID)
+			}
+			b.pmMu.Unlock()
+		}
+	})
+}
+
+// startPMURL membuat deep link untuk membuka chat pribadi dengan bot.
+func (b *Bot) startPMURL(chatID int64) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%d", b.botUsername, startPayloadJoin, chatID)
+}
+
+// handleStartPayload menangani /start dari deep link lobi: lobi grup asalnya
+// diperbarui supaya tanda "belum chat bot" hilang.
+func (b *Bot) handleStartPayload(payload string) {
+	if !strings.HasPrefix(payload, startPayloadJoin) {
+		return
+	}
+	chatID, err := strconv.ParseInt(strings.TrimPrefix(payload, startPayloadJoin), 10, 64)
+	if err != nil {
+		return
+	}
+
+	b.run(chatID, func() {
+		b.mu.RLock()
+		engine, ok := b.games[chatID]
+		inLobby := ok && engine.State.Status == game.StatusLobby
+		b.mu.RUnlock()
+		if inLobby {
+			b.updateLobbyMessage(chatID)
+		}
+	})
+}
+
+// isForbidden mengenali galat Telegram saat pengguna belum memulai atau memblokir bot.
+func isForbidden(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Forbidden")
+}
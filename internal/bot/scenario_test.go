@@ -0,0 +1,78 @@
+package bot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/telegramfake"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TestGroupGameScenario memainkan satu ronde lewat Bot.Start seperti di
+// Telegram sungguhan: lobi, petunjuk lewat PM, tebakan di grup, lalu skor.
+func TestGroupGameScenario(t *testing.T) {
+	b, client, l := newTestBot(t)
+	go b.Start()
+	t.Cleanup(client.Close)
+
+	// Update dikirim sekaligus; Start harus menanganinya sesuai urutan.
+	client.Push(telegramfake.TextUpdate(group, ani, 1, "/startgame"))
+	client.Push(telegramfake.TextUpdate(group, budi, 2, "/join"))
+	client.Push(telegramfake.TextUpdate(group, ani, 3, "/play"))
+
+	started := phrase(l, "game_started_announcement")
+	waitFor(t, time.Second, "the game to start", func() bool {
+		return countSent(client, groupID, started) > 0
+	})
+
+	prompt := phrase(l, "secret_word_prompt")
+	var giver, guesser tgbotapi.User
+	var promptMsg telegramfake.Message
+	waitFor(t, 5*time.Second, "the secret word prompt", func() bool {
+		for _, pair := range [][2]tgbotapi.User{{ani, budi}, {budi, ani}} {
+			if m, ok := client.FindSent(pair[0].ID, prompt); ok {
+				giver, guesser, promptMsg = pair[0], pair[1], m
+				return true
+			}
+		}
+		return false
+	})
+	if !strings.Contains(promptMsg.Text, "kucing") {
+		t.Fatalf("prompt does not contain the secret word: %q", promptMsg.Text)
+	}
+
+	client.Push(telegramfake.ReplyUpdate(private(giver), giver, 10, "meong", promptMsg.MessageID))
+	var clue telegramfake.Message
+	waitFor(t, time.Second, "the clue announcement", func() bool {
+		var ok bool
+		clue, ok = client.FindSent(groupID, "MEONG")
+		return ok
+	})
+
+	client.Push(telegramfake.ReplyUpdate(group, guesser, 11, "kucing", clue.MessageID))
+	title := phrase(l, "end_of_round_scoreboard_title")
+	waitFor(t, time.Second, "the round scoreboard", func() bool {
+		return countSent(client, groupID, title) > 0
+	})
+
+	won, ok := client.FindSent(groupID, phrase(l, "round_won_announcement"))
+	if !ok || !strings.Contains(won.Text, guesser.FirstName) {
+		t.Fatalf("round won announcement = %q, want winner %s", won.Text, guesser.FirstName)
+	}
+	scoreboard, _ := client.FindSent(groupID, title)
+	if !strings.Contains(scoreboard.Text, guesser.FirstName) || !strings.Contains(scoreboard.Text, "20") {
+		t.Fatalf("scoreboard = %q, want %s with 20 points", scoreboard.Text, guesser.FirstName)
+	}
+
+	// Tebakan dihapus setelah event-nya diproses, jadi bisa sedikit terlambat.
+	waitFor(t, time.Second, "the correct guess to be deleted", func() bool {
+		for _, d := range client.Deletions() {
+			if d.ChatID == groupID && d.MessageID == 11 {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,16 @@
+package bot
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TelegramClient adalah bagian dari API Telegram yang dipakai Bot. Di produksi
+// diisi *tgbotapi.BotAPI; untuk pengujian bisa diganti telegramfake.Client.
+type TelegramClient interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+}
+
+var _ TelegramClient = (*tgbotapi.BotAPI)(nil)
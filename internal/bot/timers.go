@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"time"
+
+	"detektif-kata-bot/internal/game"
+)
+
+// chatTimers menyimpan timer yang sedang berjalan untuk satu chat.
+type chatTimers map[game.TimerKind]*time.Timer
+
+// armTimer memasang timer yang diminta engine. Timer lama dengan jenis yang sama dihentikan.
+func (b *Bot) armTimer(chatID int64, t game.ArmTimer) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+	if b.stopped {
+		return
+	}
+
+	timers, ok := b.timers[chatID]
+	if !ok {
+		timers = make(chatTimers)
+		b.timers[chatID] = timers
+	}
+	if old, ok := timers[t.Timer]; ok {
+		old.Stop()
+	}
+	timers[t.Timer] = time.AfterFunc(t.After, func() {
+		b.post(chatID, game.TimeoutEvent{Timer: t.Timer, Round: t.Round})
+	})
+}
+
+func (b *Bot) stopTimer(chatID int64, kind game.TimerKind) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+
+	if timer, ok := b.timers[chatID][kind]; ok {
+		timer.Stop()
+		delete(b.timers[chatID], kind)
+	}
+}
+
+func (b *Bot) stopAllTimers(chatID int64) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+
+	for _, timer := range b.timers[chatID] {
+		timer.Stop()
+	}
+	delete(b.timers, chatID)
+}
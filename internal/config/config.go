@@ -15,6 +15,7 @@ type Config struct {
 	MustJoinChannel  string 
 	SuperAdminID     int64
 	StartImageURL    string
+	LocalStorePath   string
 }
 
 type User struct {
@@ -34,13 +35,18 @@ func Load() *Config {
 		log.Fatalf("Invalid SUPER_ADMIN_ID: %s. Must be a number.", adminIDStr)
 	}
 
+	// Dengan LOCAL_STORE_PATH, data disimpan di berkas lokal dan Supabase tidak diperlukan.
+	localStorePath := getEnv("LOCAL_STORE_PATH", false)
+	needSupabase := localStorePath == ""
+
 	return &Config{
 		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", true),
-		SupabaseURL:      getEnv("SUPABASE_URL", true),
-		SupabaseKey:      getEnv("SUPABASE_KEY", true),
+		SupabaseURL:      getEnv("SUPABASE_URL", needSupabase),
+		SupabaseKey:      getEnv("SUPABASE_KEY", needSupabase),
 		MustJoinChannel:  getEnv("MUST_JOIN_CHANNEL", false), 
 		SuperAdminID:     adminID,
 		StartImageURL:    getEnv("START_IMAGE_URL", false),
+		LocalStorePath:   localStorePath,
 	}
 }
 
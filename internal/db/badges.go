@@ -114,4 +114,27 @@ func (c *Client) GetBadgeByID(badgeID int) (*Badge, error) {
 
 	// Karena ID unik, kita ambil elemen pertama
 	return &results[0], nil
-}
\ No newline at end of file
+}
+
+// GetPlayerBadgeLinks mengambil kepemilikan lencana untuk banyak pemain sekaligus dalam satu query.
+func (c *Client) GetPlayerBadgeLinks(playerIDs []int64) ([]PlayerBadge, error) {
+	if len(playerIDs) == 0 {
+		return []PlayerBadge{}, nil
+	}
+	var results []PlayerBadge
+	filter := fmt.Sprintf("(%s)", int64SliceToCommaSeparated(playerIDs))
+	err := c.DB.From("player_badges").Select("player_id,badge_id,awarded_at").Filter("player_id", "in", filter).Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching badges for %d players: %v", len(playerIDs), err)
+		return nil, err
+	}
+	return results, nil
+}
+
+func int64SliceToCommaSeparated(ids []int64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	return stringSliceToCommaSeparated(parts)
+}
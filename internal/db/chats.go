@@ -63,4 +63,71 @@ func (c *Client) GetAllChatsByType(chatType string) ([]int64, error) {
 		chatIDs = append(chatIDs, chat.ChatID)
 	}
 	return chatIDs, nil
-}
\ No newline at end of file
+}
+
+// ChatSettings menyimpan pengaturan permainan per grup.
+type ChatSettings struct {
+	ChatID              int64 `json:"chat_id"`
+	GuessSeconds        int   `json:"guess_seconds"`
+	WarningSeconds      int   `json:"warning_seconds"`
+	ClueReminderSeconds int   `json:"clue_reminder_seconds"`
+	ClueTimeoutSeconds  int   `json:"clue_timeout_seconds"`
+	MaxMissedTurns      int   `json:"max_missed_turns"`
+	DefaultRounds       int   `json:"default_rounds"`
+	MinRounds           int   `json:"min_rounds"`
+	MaxRounds           int   `json:"max_rounds"`
+	PointTiers          []int `json:"point_tiers"`
+	NoRepeatWindow      int   `json:"no_repeat_window"`
+	MaxRerolls          int   `json:"max_rerolls"`
+	MaxPlayers          int   `json:"max_players"`
+	AutoStartSeconds    int   `json:"auto_start_seconds"`
+	LobbyExpirySeconds  int   `json:"lobby_expiry_seconds"`
+	MinPlayersForWin    int   `json:"min_players_for_win"`
+}
+
+// DefaultChatSettings mengembalikan pengaturan bawaan untuk grup yang belum mengubah apa pun.
+func DefaultChatSettings(chatID int64) *ChatSettings {
+	return &ChatSettings{
+		ChatID:              chatID,
+		GuessSeconds:        60,
+		WarningSeconds:      15,
+		ClueReminderSeconds: 90,
+		ClueTimeoutSeconds:  180,
+		MaxMissedTurns:      2,
+		DefaultRounds:       10,
+		MinRounds:           3,
+		MaxRounds:           25,
+		PointTiers:          []int{20, 15, 10, 5},
+		NoRepeatWindow:      50,
+		MaxRerolls:          2,
+		MaxPlayers:          10,
+		AutoStartSeconds:    0,
+		LobbyExpirySeconds:  600,
+		MinPlayersForWin:    3,
+	}
+}
+
+// GetChatSettings mengambil pengaturan grup, atau pengaturan bawaan jika belum ada.
+func (c *Client) GetChatSettings(chatID int64) (*ChatSettings, error) {
+	var results []ChatSettings
+	err := c.DB.From("chat_settings").Select("*").Eq("chat_id", strconv.FormatInt(chatID, 10)).Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching settings for chat %d: %v", chatID, err)
+		return DefaultChatSettings(chatID), err
+	}
+
+	if len(results) == 0 {
+		return DefaultChatSettings(chatID), nil
+	}
+	return &results[0], nil
+}
+
+// SaveChatSettings menyimpan pengaturan grup.
+func (c *Client) SaveChatSettings(settings *ChatSettings) error {
+	var results []ChatSettings
+	err := c.DB.From("chat_settings").Upsert(settings).Execute(&results)
+	if err != nil {
+		log.Printf("Error saving settings for chat %d: %v", settings.ChatID, err)
+	}
+	return err
+}
@@ -59,26 +59,41 @@ func (c *Client) GetOrCreatePlayer(tgUser *config.User) (*Player, error) {
 	return &newResults[0], nil
 }
 
-func (c *Client) AddPoints(playerID int64, pointsToAdd int) error {
-	var results []Player
-	err := c.DB.From("players").Select("points").Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(&results)
-	if err != nil || len(results) == 0 {
-		log.Printf("Error fetching player for points update: %v", err)
+// AddPoints menambah (atau mengurangi) poin pemain dalam satu UPDATE di server,
+// jadi tidak ada pembaruan lain yang tertimpa. Perubahannya dicatat di
+// points_ledger dengan alasan reason.
+func (c *Client) AddPoints(playerID int64, pointsToAdd int, reason, ref string) error {
+	var newPoints *int
+	params := map[string]interface{}{"p_player_id": playerID, "p_delta": pointsToAdd, "p_reason": reason, "p_ref": ref}
+	err := c.DB.Rpc("add_player_points", params).Execute(&newPoints)
+	if err != nil {
+		log.Printf("Error updating points for player %d: %v", playerID, err)
 		return err
 	}
+	if newPoints == nil {
+		return ErrPlayerNotFound
+	}
 
-	currentPoints := results[0].Points
-	newPoints := currentPoints + pointsToAdd
+	log.Printf("Player %d awarded %d points. New total: %d", playerID, pointsToAdd, *newPoints)
+	return nil
+}
 
-	var updateResults []interface{}
-	err = c.DB.From("players").Update(map[string]interface{}{"points": newPoints}).Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(&updateResults)
+// SpendPoints mengurangi poin hanya jika saldonya cukup, lalu mengembalikan
+// saldo barunya. Saldo yang kurang menghasilkan ErrInsufficientPoints.
+func (c *Client) SpendPoints(playerID int64, amount int, reason, ref string) (int, error) {
+	var newPoints *int
+	params := map[string]interface{}{"p_player_id": playerID, "p_amount": amount, "p_reason": reason, "p_ref": ref}
+	err := c.DB.Rpc("spend_player_points", params).Execute(&newPoints)
 	if err != nil {
-		log.Printf("Error updating points for player %d: %v", playerID, err)
-		return err
+		log.Printf("Error spending points for player %d: %v", playerID, err)
+		return 0, err
+	}
+	if newPoints == nil {
+		return 0, ErrInsufficientPoints
 	}
 
-	log.Printf("Player %d awarded %d points. New total: %d", playerID, pointsToAdd, newPoints)
-	return nil
+	log.Printf("Player %d spent %d points. New total: %d", playerID, amount, *newPoints)
+	return *newPoints, nil
 }
 
 func (c *Client) GetTopPlayers(limit int) ([]Player, error) {
@@ -101,4 +116,36 @@ func (c *Client) GetTopPlayers(limit int) ([]Player, error) {
 	}
 
 	return results, nil
+}
+
+// GetTopPlayersByWins mengambil pemain dengan kemenangan terbanyak. Jumlah
+// kemenangan yang sama diurutkan berdasarkan persentase menang.
+func (c *Client) GetTopPlayersByWins(limit int) ([]Player, error) {
+	var results []Player
+	err := c.DB.From("players").Select("*").Gt("games_won", "0").Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching players for wins leaderboard: %v", err)
+		return nil, err
+	}
+	sortByWins(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// sortByWins mengurutkan pemain dari kemenangan terbanyak, lalu dari
+// persentase menang tertinggi.
+func sortByWins(players []Player) {
+	sort.Slice(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if a.GamesWon != b.GamesWon {
+			return a.GamesWon > b.GamesWon
+		}
+		// a.GamesWon/a.GamesCounted > b.GamesWon/b.GamesCounted tanpa pembagian.
+		if a.GamesWon*b.GamesCounted != b.GamesWon*a.GamesCounted {
+			return a.GamesWon*b.GamesCounted > b.GamesWon*a.GamesCounted
+		}
+		return a.TelegramUserID < b.TelegramUserID
+	})
 }
\ No newline at end of file
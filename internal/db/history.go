@@ -0,0 +1,236 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Hasil sebuah ronde di tabel rounds.
+const (
+	RoundOutcomeWon        = "won"
+	RoundOutcomeTimeout    = "timeout"
+	RoundOutcomeSkipped    = "skipped"
+	RoundOutcomeUnfinished = "unfinished"
+)
+
+// Hasil sebuah tebakan di tabel guesses.
+const (
+	GuessCorrect = "correct"
+	GuessClose   = "close"
+	GuessWrong   = "wrong"
+)
+
+// GameRecord adalah satu permainan grup yang sudah selesai.
+type GameRecord struct {
+	ID           string    `json:"id"`
+	ChatID       int64     `json:"chat_id"`
+	Mode         string    `json:"mode"`
+	Category     string    `json:"category"`
+	RoundsPlayed int       `json:"rounds_played"`
+	TotalRounds  int       `json:"total_rounds"`
+	EndReason    string    `json:"end_reason"`
+	WinnerID     *int64    `json:"winner_id"`
+	WinCounted   bool      `json:"win_counted"`
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at"`
+}
+
+// GamePlayerRecord adalah skor akhir dan peringkat satu pemain di sebuah
+// permainan. Pemain yang seri berbagi peringkat yang sama.
+type GamePlayerRecord struct {
+	GameID    string `json:"game_id"`
+	PlayerID  int64  `json:"player_id"`
+	FirstName string `json:"first_name"`
+	Points    int    `json:"points"`
+	LeftEarly bool   `json:"left_early"`
+	Placement int    `json:"placement"`
+	Won       bool   `json:"won"`
+}
+
+// RoundRecord adalah satu ronde: kata, petunjuk, dan siapa yang menebaknya.
+type RoundRecord struct {
+	GameID       string   `json:"game_id"`
+	Round        int      `json:"round"`
+	ClueGiverID  int64    `json:"clue_giver_id"`
+	Word         string   `json:"word"`
+	Clue         string   `json:"clue"`
+	Outcome      string   `json:"outcome"`
+	WinnerID     *int64   `json:"winner_id"`
+	GuessSeconds *float64 `json:"guess_seconds"`
+}
+
+// GuessRecord adalah satu tebakan di sebuah ronde. Seconds dihitung sejak petunjuk diumumkan.
+type GuessRecord struct {
+	GameID   string  `json:"game_id"`
+	Round    int     `json:"round"`
+	PlayerID int64   `json:"player_id"`
+	Text     string  `json:"text"`
+	Result   string  `json:"result"`
+	Seconds  float64 `json:"seconds"`
+}
+
+// GameDetail adalah satu permainan beserta pemain, ronde, dan tebakannya.
+type GameDetail struct {
+	Game    GameRecord
+	Players []GamePlayerRecord
+	Rounds  []RoundRecord
+	Guesses []GuessRecord
+}
+
+// PlayerHistoryStats adalah statistik seorang pemain yang dihitung dari riwayat permainan.
+type PlayerHistoryStats struct {
+	Games           int
+	TotalPoints     int
+	BestPoints      int
+	CluesGiven      int
+	CluesSolved     int
+	CorrectGuesses  int
+	WrongGuesses    int
+	AvgGuessSeconds float64
+}
+
+// SaveGameRecord menyimpan satu permainan yang sudah selesai lewat satu RPC,
+// jadi keempat tabel riwayat ditulis dalam satu transaksi.
+func (c *Client) SaveGameRecord(detail *GameDetail) error {
+	var saved bool
+	params := map[string]interface{}{
+		"p_game":    detail.Game,
+		"p_players": detail.Players,
+		"p_rounds":  detail.Rounds,
+		"p_guesses": detail.Guesses,
+	}
+	if err := c.DB.Rpc("save_game_record", params).Execute(&saved); err != nil {
+		log.Printf("Error saving game %s: %v", detail.Game.ID, err)
+		return err
+	}
+	return nil
+}
+
+// GetRecentGames mengambil permainan terakhir di sebuah grup, yang terbaru lebih dulu.
+func (c *Client) GetRecentGames(chatID int64, limit int) ([]GameRecord, error) {
+	var results []GameRecord
+	err := c.DB.From("games").Select("*").
+		OrderBy("ended_at", "desc").
+		Limit(limit).
+		Eq("chat_id", strconv.FormatInt(chatID, 10)).
+		Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching games for chat %d: %v", chatID, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetGameDetail mengambil satu permainan beserta pemain, ronde, dan tebakannya.
+func (c *Client) GetGameDetail(gameID string) (*GameDetail, error) {
+	var games []GameRecord
+	err := c.DB.From("games").Select("*").Eq("id", gameID).Execute(&games)
+	if err != nil {
+		log.Printf("Error fetching game %s: %v", gameID, err)
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("game %s not found", gameID)
+	}
+
+	detail := &GameDetail{Game: games[0]}
+	if err := c.DB.From("game_players").Select("*").Eq("game_id", gameID).Execute(&detail.Players); err != nil {
+		log.Printf("Error fetching players of game %s: %v", gameID, err)
+		return nil, err
+	}
+	if err := c.DB.From("rounds").Select("*").Eq("game_id", gameID).Execute(&detail.Rounds); err != nil {
+		log.Printf("Error fetching rounds of game %s: %v", gameID, err)
+		return nil, err
+	}
+	if err := c.DB.From("guesses").Select("game_id,round,player_id,text,result,seconds").Eq("game_id", gameID).Execute(&detail.Guesses); err != nil {
+		log.Printf("Error fetching guesses of game %s: %v", gameID, err)
+		return nil, err
+	}
+	detail.sort()
+	return detail, nil
+}
+
+// GetPlayerHistoryStats menghitung statistik pemain dari tabel riwayat permainan.
+func (c *Client) GetPlayerHistoryStats(playerID int64) (*PlayerHistoryStats, error) {
+	id := strconv.FormatInt(playerID, 10)
+
+	var players []GamePlayerRecord
+	if err := c.DB.From("game_players").Select("*").Eq("player_id", id).Execute(&players); err != nil {
+		log.Printf("Error fetching game history for player %d: %v", playerID, err)
+		return nil, err
+	}
+	var rounds []RoundRecord
+	if err := c.DB.From("rounds").Select("*").Eq("clue_giver_id", id).Execute(&rounds); err != nil {
+		log.Printf("Error fetching clue history for player %d: %v", playerID, err)
+		return nil, err
+	}
+	var guesses []GuessRecord
+	if err := c.DB.From("guesses").Select("result,seconds").Eq("player_id", id).Execute(&guesses); err != nil {
+		log.Printf("Error fetching guess history for player %d: %v", playerID, err)
+		return nil, err
+	}
+	return historyStats(players, rounds, guesses), nil
+}
+
+// latestGames mengurutkan permainan dari yang paling baru selesai dan memotongnya sampai limit.
+func latestGames(games []GameRecord, limit int) []GameRecord {
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].EndedAt.After(games[j].EndedAt)
+	})
+	if len(games) > limit {
+		games = games[:limit]
+	}
+	return games
+}
+
+// sort mengurutkan pemain dari skor tertinggi, serta ronde dan tebakan sesuai urutan permainan.
+func (d *GameDetail) sort() {
+	sort.SliceStable(d.Players, func(i, j int) bool {
+		return d.Players[i].Points > d.Players[j].Points
+	})
+	sort.Slice(d.Rounds, func(i, j int) bool {
+		return d.Rounds[i].Round < d.Rounds[j].Round
+	})
+	sort.SliceStable(d.Guesses, func(i, j int) bool {
+		if d.Guesses[i].Round != d.Guesses[j].Round {
+			return d.Guesses[i].Round < d.Guesses[j].Round
+		}
+		return d.Guesses[i].Seconds < d.Guesses[j].Seconds
+	})
+}
+
+func historyStats(players []GamePlayerRecord, rounds []RoundRecord, guesses []GuessRecord) *PlayerHistoryStats {
+	stats := &PlayerHistoryStats{Games: len(players)}
+	for _, p := range players {
+		stats.TotalPoints += p.Points
+		if p.Points > stats.BestPoints {
+			stats.BestPoints = p.Points
+		}
+	}
+	for _, r := range rounds {
+		switch r.Outcome {
+		case RoundOutcomeWon:
+			stats.CluesGiven++
+			stats.CluesSolved++
+		case RoundOutcomeTimeout:
+			stats.CluesGiven++
+		}
+	}
+	var correctSeconds float64
+	for _, g := range guesses {
+		switch g.Result {
+		case GuessCorrect:
+			stats.CorrectGuesses++
+			correctSeconds += g.Seconds
+		default:
+			stats.WrongGuesses++
+		}
+	}
+	if stats.CorrectGuesses > 0 {
+		stats.AvgGuessSeconds = correctSeconds / float64(stats.CorrectGuesses)
+	}
+	return stats
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Alasan perubahan saldo yang dicatat di points_ledger.
+const (
+	LedgerOpeningBalance = "opening_balance"
+	LedgerGamePayout     = "game_payout"
+	LedgerSoloWin        = "solo_win"
+	LedgerPurchase       = "shop_purchase"
+	LedgerRefund         = "refund"
+)
+
+// LedgerEntry adalah satu perubahan saldo poin beserta saldo sesudahnya.
+// Ref menunjuk sumber perubahan, misalnya ID chat atau ID lencana.
+type LedgerEntry struct {
+	ID        int64     `json:"id,omitempty"`
+	PlayerID  int64     `json:"player_id"`
+	Delta     int       `json:"delta"`
+	Balance   int       `json:"balance"`
+	Reason    string    `json:"reason"`
+	Ref       string    `json:"ref"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LedgerTotals adalah ringkasan ledger seorang pemain. Sum harus sama dengan
+// saldo pemain.
+type LedgerTotals struct {
+	Entries int `json:"entries"`
+	Sum     int `json:"total"`
+}
+
+// GetPointsLedger mengambil satu halaman riwayat poin seorang pemain, yang
+// terbaru lebih dulu. Pengurutan dan pembatasan dilakukan di server.
+func (c *Client) GetPointsLedger(playerID int64, limit, offset int) ([]LedgerEntry, error) {
+	var results []LedgerEntry
+	err := c.DB.From("points_ledger").Select("*").
+		OrderBy("id", "desc").
+		LimitWithOffset(limit, offset).
+		Eq("player_id", strconv.FormatInt(playerID, 10)).
+		Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching points ledger for player %d: %v", playerID, err)
+		return nil, err
+	}
+	return results, nil
+}
+
+// GetLedgerTotals menghitung jumlah entri dan total perubahan ledger di server.
+func (c *Client) GetLedgerTotals(playerID int64) (*LedgerTotals, error) {
+	var results []LedgerTotals
+	params := map[string]interface{}{"p_player_id": playerID}
+	if err := c.DB.Rpc("points_ledger_totals", params).Execute(&results); err != nil {
+		log.Printf("Error fetching ledger totals for player %d: %v", playerID, err)
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &LedgerTotals{}, nil
+	}
+	return &results[0], nil
+}
+
+func sortLedger(entries []LedgerEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+}
@@ -0,0 +1,722 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"detektif-kata-bot/internal/config"
+)
+
+// LocalStore menyimpan semua data di memori dan menuliskannya ke sebuah berkas
+// JSON setiap kali ada perubahan, jadi bot bisa berjalan tanpa Supabase.
+// Path kosong berarti data hanya disimpan di memori.
+type LocalStore struct {
+	mu   sync.Mutex
+	path string
+	data localData
+	// saved adalah isi berkas yang terakhir ditulis, supaya save tidak
+	// menulis ulang berkas jika datanya tidak berubah.
+	saved []byte
+}
+
+// localData adalah isi berkas JSON. Setiap field mewakili satu tabel Supabase.
+type localData struct {
+	Players      map[int64]*Player       `json:"players"`
+	Badges       []Badge                 `json:"badges"`
+	PlayerBadges []PlayerBadge           `json:"player_badges"`
+	Chats        map[int64]string        `json:"chats"`
+	ChatSettings map[int64]*ChatSettings `json:"chat_settings"`
+	Snapshots    []GameSnapshot          `json:"game_snapshots"`
+	Words        []Word                  `json:"words"`
+	WordHistory  []WordUsage             `json:"word_history"`
+	WordRerolls  []WordReroll            `json:"word_rerolls"`
+	PointsLedger []LedgerEntry           `json:"points_ledger"`
+	Games        []GameRecord            `json:"games"`
+	GamePlayers  []GamePlayerRecord      `json:"game_players"`
+	Rounds       []RoundRecord           `json:"rounds"`
+	Guesses      []GuessRecord           `json:"guesses"`
+}
+
+var _ Store = (*LocalStore)(nil)
+
+// NewLocalStore membuka berkas data di path, atau memulai data kosong jika
+// berkasnya belum ada.
+func NewLocalStore(path string) (*LocalStore, error) {
+	s := &LocalStore{path: path}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &s.data); err != nil {
+				return nil, fmt.Errorf("reading local store %s: %w", path, err)
+			}
+			s.saved = raw
+		}
+	}
+	if s.data.Players == nil {
+		s.data.Players = make(map[int64]*Player)
+	}
+	for _, p := range s.data.Players {
+		// Berkas dari versi sebelum ada games_counted; lihat migrasi 020.
+		if p.GamesCounted < p.GamesWon {
+			p.GamesCounted = p.GamesWon
+		}
+	}
+	if s.data.Chats == nil {
+		s.data.Chats = make(map[int64]string)
+	}
+	if s.data.ChatSettings == nil {
+		s.data.ChatSettings = make(map[int64]*ChatSettings)
+	}
+	if s.data.PointsLedger == nil {
+		// Berkas dari versi sebelum ada ledger: catat saldo yang ada sebagai saldo awal.
+		s.data.PointsLedger = make([]LedgerEntry, 0)
+		for _, p := range s.data.Players {
+			if p.Points != 0 {
+				s.appendLedger(p, p.Points, LedgerOpeningBalance, "")
+			}
+		}
+	}
+	log.Printf("Using local store at %q.", path)
+	return s, nil
+}
+
+// save menulis seluruh data ke berkas sementara lalu menggantikan berkas lama,
+// supaya berkas tidak rusak jika bot mati di tengah penulisan. Berkas tidak
+// ditulis jika isinya sama dengan penulisan terakhir. Dipanggil dengan s.mu
+// terkunci.
+func (s *LocalStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(raw, s.saved) {
+		return nil
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		log.Printf("Error writing local store: %v", err)
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		log.Printf("Error replacing local store: %v", err)
+		return err
+	}
+	s.saved = raw
+	return nil
+}
+
+func (s *LocalStore) player(playerID int64) (*Player, error) {
+	p, ok := s.data.Players[playerID]
+	if !ok {
+		return nil, ErrPlayerNotFound
+	}
+	return p, nil
+}
+
+func (s *LocalStore) GetOrCreatePlayer(tgUser *config.User) (*Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.data.Players[tgUser.ID]; ok {
+		result := *p
+		return &result, nil
+	}
+
+	log.Printf("Player not found. Creating new player: %s (ID: %d)", tgUser.FirstName, tgUser.ID)
+	p := &Player{
+		TelegramUserID: tgUser.ID,
+		FirstName:      tgUser.FirstName,
+		Username:       tgUser.Username,
+		CreatedAt:      time.Now(),
+		FastestGuess:   -1,
+	}
+	s.data.Players[tgUser.ID] = p
+	if err := s.save(); err != nil {
+		return nil, err
+	}
+	result := *p
+	return &result, nil
+}
+
+func (s *LocalStore) GetPlayerByID(playerID int64) (*Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.data.Players[playerID]
+	if !ok {
+		return nil, nil
+	}
+	result := *p
+	return &result, nil
+}
+
+func (s *LocalStore) GetPlayersByIDs(playerIDs []int64) ([]Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := []Player{}
+	for _, id := range playerIDs {
+		if p, ok := s.data.Players[id]; ok {
+			results = append(results, *p)
+		}
+	}
+	return results, nil
+}
+
+func (s *LocalStore) GetTopPlayers(limit int) ([]Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []Player
+	for _, p := range s.data.Players {
+		if p.Points > 0 {
+			results = append(results, *p)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Points > results[j].Points
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *LocalStore) GetTopPlayersByWins(limit int) ([]Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []Player
+	for _, p := range s.data.Players {
+		if p.GamesWon > 0 {
+			results = append(results, *p)
+		}
+	}
+	sortByWins(results)
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *LocalStore) UpdatePlayerRatings(updates []RatingUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range updates {
+		p, ok := s.data.Players[u.PlayerID]
+		if !ok {
+			continue
+		}
+		p.Rating = u.Rating.Value
+		p.RatingDeviation = u.Rating.Deviation
+		p.RatedGames++
+		p.RatedAt = u.Rating.RatedAt
+	}
+	return s.save()
+}
+
+func (s *LocalStore) GetTopPlayersByRating(limit int) ([]Player, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []Player
+	for _, p := range s.data.Players {
+		if p.RatedGames > 0 {
+			results = append(results, *p)
+		}
+	}
+	sortByRating(results, time.Now())
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// appendLedger mencatat perubahan saldo p yang baru saja terjadi. Dipanggil
+// dengan s.mu terkunci.
+func (s *LocalStore) appendLedger(p *Player, delta int, reason, ref string) {
+	s.data.PointsLedger = append(s.data.PointsLedger, LedgerEntry{
+		ID:        int64(len(s.data.PointsLedger) + 1),
+		PlayerID:  p.TelegramUserID,
+		Delta:     delta,
+		Balance:   p.Points,
+		Reason:    reason,
+		Ref:       ref,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *LocalStore) AddPoints(playerID int64, pointsToAdd int, reason, ref string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		log.Printf("Error fetching player for points update: %v", err)
+		return err
+	}
+	p.Points += pointsToAdd
+	s.appendLedger(p, pointsToAdd, reason, ref)
+	log.Printf("Player %d awarded %d points. New total: %d", playerID, pointsToAdd, p.Points)
+	return s.save()
+}
+
+func (s *LocalStore) SpendPoints(playerID int64, amount int, reason, ref string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		return 0, err
+	}
+	if p.Points < amount {
+		return 0, ErrInsufficientPoints
+	}
+	p.Points -= amount
+	s.appendLedger(p, -amount, reason, ref)
+	log.Printf("Player %d spent %d points. New total: %d", playerID, amount, p.Points)
+	return p.Points, s.save()
+}
+
+func (s *LocalStore) GetPointsLedger(playerID int64, limit, offset int) ([]LedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []LedgerEntry
+	for _, e := range s.data.PointsLedger {
+		if e.PlayerID == playerID {
+			results = append(results, e)
+		}
+	}
+	sortLedger(results)
+	if offset >= len(results) {
+		return nil, nil
+	}
+	results = results[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *LocalStore) GetLedgerTotals(playerID int64) (*LedgerTotals, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totals := &LedgerTotals{}
+	for _, e := range s.data.PointsLedger {
+		if e.PlayerID == playerID {
+			totals.Entries++
+			totals.Sum += e.Delta
+		}
+	}
+	return totals, nil
+}
+
+func (s *LocalStore) IncrementPlayerStats(playerID int64, field string, value int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		log.Printf("Error fetching player for stats update: %v", err)
+		return err
+	}
+	switch field {
+	case "games_played":
+		p.GamesPlayed += value
+	case "games_won":
+		p.GamesWon += value
+	case "clue_given_count":
+		p.ClueGivenCount += value
+	case "clue_success_count":
+		p.ClueSuccessCount += value
+	case "words_guessed_count":
+		p.WordsGuessedCount += value
+	case "missed_turns_count":
+		p.MissedTurnsCount += value
+	default:
+		return fmt.Errorf("unknown player stat %q", field)
+	}
+	return s.save()
+}
+
+func (s *LocalStore) UpdatePlayerFastestGuess(playerID int64, newTime float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		return err
+	}
+	if p.FastestGuess == -1 || newTime < p.FastestGuess {
+		p.FastestGuess = newTime
+		return s.save()
+	}
+	return nil
+}
+
+func (s *LocalStore) RecordGameResult(playerID int64, won bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		log.Printf("Error recording game result for player %d: %v", playerID, err)
+		return err
+	}
+	p.GamesCounted++
+	if won {
+		p.GamesWon++
+		p.WinStreak++
+	} else {
+		p.WinStreak = 0
+	}
+	if p.WinStreak > p.BestWinStreak {
+		p.BestWinStreak = p.WinStreak
+	}
+	return s.save()
+}
+
+func (s *LocalStore) SetEquippedBadge(playerID int64, badgeID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		log.Printf("Error setting equipped badge for player %d: %v", playerID, err)
+		return err
+	}
+	p.EquippedBadgeID = &badgeID
+	return s.save()
+}
+
+func (s *LocalStore) SetPlayerPMReachable(playerID int64, reachable bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, err := s.player(playerID)
+	if err != nil {
+		log.Printf("Error updating PM reachability for player %d: %v", playerID, err)
+		return err
+	}
+	p.CanReceivePM = reachable
+	return s.save()
+}
+
+// badgesWhere mengembalikan salinan lencana yang lolos filter keep.
+func (s *LocalStore) badgesWhere(keep func(Badge) bool) []Badge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	badges := []Badge{}
+	for _, badge := range s.data.Badges {
+		if keep(badge) {
+			badges = append(badges, badge)
+		}
+	}
+	return badges
+}
+
+func (s *LocalStore) GetAllBadges() ([]Badge, error) {
+	return s.badgesWhere(func(Badge) bool { return true }), nil
+}
+
+func (s *LocalStore) GetAchievementBadges() ([]Badge, error) {
+	return s.badgesWhere(func(b Badge) bool { return b.Type == "achievement" }), nil
+}
+
+func (s *LocalStore) GetPurchasableBadges() ([]Badge, error) {
+	return s.badgesWhere(func(b Badge) bool { return b.Type == "purchasable" }), nil
+}
+
+func (s *LocalStore) GetBadgeByID(badgeID int) (*Badge, error) {
+	badges := s.badgesWhere(func(b Badge) bool { return b.ID == badgeID })
+	if len(badges) == 0 {
+		return nil, fmt.Errorf("badge with ID %d not found", badgeID)
+	}
+	return &badges[0], nil
+}
+
+func (s *LocalStore) GetPlayerBadges(playerID int64) ([]Badge, error) {
+	s.mu.Lock()
+	owned := make(map[int]bool)
+	for _, pb := range s.data.PlayerBadges {
+		if pb.PlayerID == playerID {
+			owned[pb.BadgeID] = true
+		}
+	}
+	s.mu.Unlock()
+
+	return s.badgesWhere(func(b Badge) bool { return owned[b.ID] }), nil
+}
+
+func (s *LocalStore) GetPlayerBadgeLinks(playerIDs []int64) ([]PlayerBadge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wanted := make(map[int64]bool, len(playerIDs))
+	for _, id := range playerIDs {
+		wanted[id] = true
+	}
+	results := []PlayerBadge{}
+	for _, pb := range s.data.PlayerBadges {
+		if wanted[pb.PlayerID] {
+			results = append(results, pb)
+		}
+	}
+	return results, nil
+}
+
+func (s *LocalStore) AwardBadgeToPlayer(playerID int64, badgeID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pb := range s.data.PlayerBadges {
+		if pb.PlayerID == playerID && pb.BadgeID == badgeID {
+			err := fmt.Errorf("player %d already owns badge %d", playerID, badgeID)
+			log.Printf("Could not award badge %d to player %d (maybe already owned): %v", badgeID, playerID, err)
+			return err
+		}
+	}
+	now := time.Now()
+	s.data.PlayerBadges = append(s.data.PlayerBadges, PlayerBadge{PlayerID: playerID, BadgeID: badgeID, AwardedAt: &now})
+	log.Printf("Awarded badge %d to player %d successfully.", badgeID, playerID)
+	return s.save()
+}
+
+func (s *LocalStore) GetOrCreateChat(chatID int64, chatType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.Chats[chatID]; ok {
+		return nil
+	}
+	log.Printf("Chat not found. Creating new chat entry: %d (%s)", chatID, chatType)
+	s.data.Chats[chatID] = chatType
+	return s.save()
+}
+
+func (s *LocalStore) GetAllChatsByType(chatType string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var chatIDs []int64
+	for id, t := range s.data.Chats {
+		if t == chatType || (chatType == "group" && t == "supergroup") {
+			chatIDs = append(chatIDs, id)
+		}
+	}
+	return chatIDs, nil
+}
+
+func (s *LocalStore) GetChatSettings(chatID int64) (*ChatSettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, ok := s.data.ChatSettings[chatID]
+	if !ok {
+		return DefaultChatSettings(chatID), nil
+	}
+	result := *settings
+	result.PointTiers = append([]int(nil), settings.PointTiers...)
+	return &result, nil
+}
+
+func (s *LocalStore) SaveChatSettings(settings *ChatSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := *settings
+	result.PointTiers = append([]int(nil), settings.PointTiers...)
+	s.data.ChatSettings[settings.ChatID] = &result
+	return s.save()
+}
+
+func (s *LocalStore) SaveGameSnapshot(kind string, chatID int64, state []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := GameSnapshot{Kind: kind, ChatID: chatID, State: append([]byte(nil), state...), UpdatedAt: time.Now()}
+	for i, existing := range s.data.Snapshots {
+		if existing.Kind == kind && existing.ChatID == chatID {
+			if bytes.Equal(existing.State, state) {
+				return nil
+			}
+			s.data.Snapshots[i] = snapshot
+			return s.save()
+		}
+	}
+	s.data.Snapshots = append(s.data.Snapshots, snapshot)
+	return s.save()
+}
+
+func (s *LocalStore) DeleteGameSnapshot(kind string, chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.data.Snapshots {
+		if existing.Kind == kind && existing.ChatID == chatID {
+			s.data.Snapshots = append(s.data.Snapshots[:i], s.data.Snapshots[i+1:]...)
+			return s.save()
+		}
+	}
+	return nil
+}
+
+func (s *LocalStore) GetGameSnapshots() ([]GameSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]GameSnapshot(nil), s.data.Snapshots...), nil
+}
+
+func (s *LocalStore) SaveGameRecord(detail *GameDetail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, g := range s.data.Games {
+		if g.ID == detail.Game.ID {
+			return fmt.Errorf("game %s already recorded", g.ID)
+		}
+	}
+	s.data.Games = append(s.data.Games, detail.Game)
+	s.data.GamePlayers = append(s.data.GamePlayers, detail.Players...)
+	s.data.Rounds = append(s.data.Rounds, detail.Rounds...)
+	s.data.Guesses = append(s.data.Guesses, detail.Guesses...)
+	return s.save()
+}
+
+func (s *LocalStore) GetRecentGames(chatID int64, limit int) ([]GameRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []GameRecord
+	for _, g := range s.data.Games {
+		if g.ChatID == chatID {
+			results = append(results, g)
+		}
+	}
+	return latestGames(results, limit), nil
+}
+
+func (s *LocalStore) GetGameDetail(gameID string) (*GameDetail, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var detail *GameDetail
+	for _, g := range s.data.Games {
+		if g.ID == gameID {
+			detail = &GameDetail{Game: g}
+		}
+	}
+	if detail == nil {
+		return nil, fmt.Errorf("game %s not found", gameID)
+	}
+	for _, p := range s.data.GamePlayers {
+		if p.GameID == gameID {
+			detail.Players = append(detail.Players, p)
+		}
+	}
+	for _, r := range s.data.Rounds {
+		if r.GameID == gameID {
+			detail.Rounds = append(detail.Rounds, r)
+		}
+	}
+	for _, g := range s.data.Guesses {
+		if g.GameID == gameID {
+			detail.Guesses = append(detail.Guesses, g)
+		}
+	}
+	detail.sort()
+	return detail, nil
+}
+
+func (s *LocalStore) GetPlayerHistoryStats(playerID int64) (*PlayerHistoryStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var players []GamePlayerRecord
+	for _, p := range s.data.GamePlayers {
+		if p.PlayerID == playerID {
+			players = append(players, p)
+		}
+	}
+	var rounds []RoundRecord
+	for _, r := range s.data.Rounds {
+		if r.ClueGiverID == playerID {
+			rounds = append(rounds, r)
+		}
+	}
+	var guesses []GuessRecord
+	for _, g := range s.data.Guesses {
+		if g.PlayerID == playerID {
+			guesses = append(guesses, g)
+		}
+	}
+	return historyStats(players, rounds, guesses), nil
+}
+
+// GetWords mengembalikan katalog kata dari berkas. Katalog kosong membuat bot
+// memakai daftar kata bawaan.
+func (s *LocalStore) GetWords() ([]Word, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Word(nil), s.data.Words...), nil
+}
+
+func (s *LocalStore) RecordWordUsage(chatID int64, word string, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, usage := range s.data.WordHistory {
+		if usage.ChatID == chatID && usage.Word == word {
+			s.data.WordHistory[i].UsedAt = usedAt
+			return s.save()
+		}
+	}
+	s.data.WordHistory = append(s.data.WordHistory, WordUsage{ChatID: chatID, Word: word, UsedAt: usedAt})
+	return s.save()
+}
+
+func (s *LocalStore) GetWordHistory(chatID int64, limit int) ([]WordUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []WordUsage
+	for _, usage := range s.data.WordHistory {
+		if usage.ChatID == chatID {
+			results = append(results, usage)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UsedAt.After(results[j].UsedAt)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (s *LocalStore) RecordWordReroll(chatID int64, playerID int64, word string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.WordRerolls = append(s.data.WordRerolls, WordReroll{ChatID: chatID, PlayerID: playerID, Word: word, RerolledAt: time.Now()})
+	return s.save()
+}
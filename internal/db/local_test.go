@@ -0,0 +1,326 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/config"
+)
+
+func newTestStore(t *testing.T) (*LocalStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "store.json")
+	s, err := NewLocalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, path
+}
+
+func mustCreatePlayer(t *testing.T, s *LocalStore, id int64, name string) *Player {
+	t.Helper()
+	p, err := s.GetOrCreatePlayer(&config.User{ID: id, FirstName: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLocalStorePlayers(t *testing.T) {
+	s, path := newTestStore(t)
+
+	created := mustCreatePlayer(t, s, 1, "Ani")
+	if created.FirstName != "Ani" || created.FastestGuess != -1 {
+		t.Fatalf("created player = %+v", created)
+	}
+
+	// Nama baru tidak menimpa pemain yang sudah ada.
+	again, err := s.GetOrCreatePlayer(&config.User{ID: 1, FirstName: "Bukan Ani"})
+	if err != nil || again.FirstName != "Ani" {
+		t.Fatalf("GetOrCreatePlayer on existing player = %+v, %v", again, err)
+	}
+
+	missing, err := s.GetPlayerByID(2)
+	if missing != nil || err != nil {
+		t.Fatalf("GetPlayerByID(missing) = %+v, %v, want nil, nil", missing, err)
+	}
+
+	// Salinan yang dikembalikan tidak boleh mengubah data di store.
+	created.Points = 999
+	reopened, err := NewLocalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.GetPlayerByID(1)
+	if err != nil || got == nil || got.FirstName != "Ani" || got.Points != 0 {
+		t.Fatalf("player after reopen = %+v, %v", got, err)
+	}
+}
+
+func TestLocalStoreSpendPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		balance     int
+		spend       int
+		wantErr     error
+		wantBalance int
+	}{
+		{name: "enough points", balance: 50, spend: 30, wantBalance: 20},
+		{name: "exact balance", balance: 30, spend: 30, wantBalance: 0},
+		{name: "too few points", balance: 20, spend: 30, wantErr: ErrInsufficientPoints, wantBalance: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestStore(t)
+			mustCreatePlayer(t, s, 1, "Ani")
+			if err := s.AddPoints(1, tt.balance, LedgerGamePayout, "-100"); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := s.SpendPoints(1, tt.spend, LedgerPurchase, "badge:1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SpendPoints error = %v, want %v", err, tt.wantErr)
+			}
+			p, _ := s.GetPlayerByID(1)
+			if p.Points != tt.wantBalance {
+				t.Errorf("balance = %d, want %d", p.Points, tt.wantBalance)
+			}
+			totals, _ := s.GetLedgerTotals(1)
+			if totals.Sum != p.Points {
+				t.Errorf("ledger sum = %d, balance = %d", totals.Sum, p.Points)
+			}
+		})
+	}
+
+	s, _ := newTestStore(t)
+	if _, err := s.SpendPoints(42, 1, LedgerPurchase, ""); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("SpendPoints for missing player error = %v, want %v", err, ErrPlayerNotFound)
+	}
+}
+
+func TestLocalStoreRecordGameResult(t *testing.T) {
+	s, _ := newTestStore(t)
+	mustCreatePlayer(t, s, 1, "Ani")
+	for _, won := range []bool{true, true, false, true} {
+		if err := s.RecordGameResult(1, won); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, _ := s.GetPlayerByID(1)
+	if p.GamesCounted != 4 || p.GamesWon != 3 || p.WinStreak != 1 || p.BestWinStreak != 2 {
+		t.Errorf("player after results = counted %d, won %d, streak %d, best %d; want 4, 3, 1, 2",
+			p.GamesCounted, p.GamesWon, p.WinStreak, p.BestWinStreak)
+	}
+	// games_played tidak diubah oleh hasil permainan.
+	if p.GamesPlayed != 0 {
+		t.Errorf("games played = %d, want 0", p.GamesPlayed)
+	}
+}
+
+func TestSortByWins(t *testing.T) {
+	players := []Player{
+		// Banyak permainan yang tidak dihitung tidak menurunkan peringkat Ani.
+		{TelegramUserID: 1, FirstName: "Ani", GamesWon: 4, GamesPlayed: 20, GamesCounted: 5},
+		{TelegramUserID: 2, FirstName: "Budi", GamesWon: 4, GamesPlayed: 8, GamesCounted: 8},
+		{TelegramUserID: 3, FirstName: "Citra", GamesWon: 6, GamesPlayed: 30, GamesCounted: 30},
+		{TelegramUserID: 4, FirstName: "Dodi", GamesWon: 4, GamesPlayed: 9, GamesCounted: 8},
+	}
+	sortByWins(players)
+
+	var got []int64
+	for _, p := range players {
+		got = append(got, p.TelegramUserID)
+	}
+	want := []int64{3, 1, 2, 4}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLocalStoreLedger(t *testing.T) {
+	s, _ := newTestStore(t)
+	mustCreatePlayer(t, s, 1, "Ani")
+	mustCreatePlayer(t, s, 2, "Budi")
+
+	s.AddPoints(1, 20, LedgerGamePayout, "-100")
+	s.AddPoints(2, 5, LedgerSoloWin, "")
+	s.SpendPoints(1, 15, LedgerPurchase, "badge:3")
+	s.AddPoints(1, 15, LedgerRefund, "badge:3")
+
+	ledger, err := s.GetPointsLedger(1, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		delta, balance int
+		reason         string
+	}{
+		{15, 20, LedgerRefund},
+		{-15, 5, LedgerPurchase},
+		{20, 20, LedgerGamePayout},
+	}
+	if len(ledger) != len(want) {
+		t.Fatalf("ledger has %d entries, want %d: %+v", len(ledger), len(want), ledger)
+	}
+	for i, w := range want {
+		e := ledger[i]
+		if e.PlayerID != 1 || e.Delta != w.delta || e.Balance != w.balance || e.Reason != w.reason {
+			t.Errorf("entry %d = %+v, want delta %d balance %d reason %s", i, e, w.delta, w.balance, w.reason)
+		}
+	}
+
+	page, _ := s.GetPointsLedger(1, 2, 1)
+	if len(page) != 2 || page[0].ID != ledger[1].ID || page[1].ID != ledger[2].ID {
+		t.Errorf("second page = %+v, want entries 2 and 3", page)
+	}
+	if past, _ := s.GetPointsLedger(1, 2, 3); len(past) != 0 {
+		t.Errorf("page past the end = %+v, want none", past)
+	}
+	totals, _ := s.GetLedgerTotals(1)
+	if totals.Entries != 3 || totals.Sum != 20 {
+		t.Errorf("totals = %+v, want 3 entries summing to 20", totals)
+	}
+}
+
+func TestLocalStoreOpeningBalance(t *testing.T) {
+	// Berkas lama tanpa points_ledger mendapat entri saldo awal.
+	path := filepath.Join(t.TempDir(), "store.json")
+	old := `{"players": {"1": {"telegram_user_id": 1, "first_name": "Ani", "points": 70}}}`
+	if err := os.WriteFile(path, []byte(old), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewLocalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ledger, _ := s.GetPointsLedger(1, 10, 0)
+	if len(ledger) != 1 || ledger[0].Reason != LedgerOpeningBalance || ledger[0].Balance != 70 {
+		t.Fatalf("ledger = %+v, want one opening balance of 70", ledger)
+	}
+}
+
+func TestLocalStoreSnapshots(t *testing.T) {
+	s, path := newTestStore(t)
+
+	s.SaveGameSnapshot(SnapshotGroup, -100, []byte(`{"round":1}`))
+	s.SaveGameSnapshot(SnapshotGroup, -100, []byte(`{"round":2}`))
+	s.SaveGameSnapshot(SnapshotSolo, 1, []byte(`{"word":"kucing"}`))
+
+	reopened, err := NewLocalStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshots, _ := reopened.GetGameSnapshots()
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2: %+v", len(snapshots), snapshots)
+	}
+	for _, snap := range snapshots {
+		var state bytes.Buffer
+		json.Compact(&state, snap.State)
+		if snap.Kind == SnapshotGroup && state.String() != `{"round":2}` {
+			t.Errorf("group snapshot = %s, want the latest state", snap.State)
+		}
+	}
+
+	if err := reopened.DeleteGameSnapshot(SnapshotGroup, -100); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.DeleteGameSnapshot(SnapshotGroup, -100); err != nil {
+		t.Errorf("deleting a missing snapshot returned %v", err)
+	}
+	snapshots, _ = reopened.GetGameSnapshots()
+	if len(snapshots) != 1 || snapshots[0].Kind != SnapshotSolo {
+		t.Errorf("snapshots after delete = %+v, want only the solo one", snapshots)
+	}
+}
+
+func TestLocalStoreHistory(t *testing.T) {
+	s, _ := newTestStore(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	winner := int64(2)
+	seconds := 8.0
+
+	for i, id := range []string{"g1", "g2", "g3"} {
+		detail := &GameDetail{
+			Game: GameRecord{ID: id, ChatID: -100, RoundsPlayed: 1, TotalRounds: 1, WinnerID: &winner, StartedAt: start, EndedAt: start.Add(time.Duration(i) * time.Hour)},
+			Players: []GamePlayerRecord{
+				{GameID: id, PlayerID: 1, FirstName: "Ani", Placement: 2},
+				{GameID: id, PlayerID: 2, FirstName: "Budi", Points: 20 - 5*i, Placement: 1, Won: true},
+			},
+			Rounds: []RoundRecord{
+				{GameID: id, Round: 1, ClueGiverID: 1, Word: "kucing", Clue: "meong", Outcome: RoundOutcomeWon, WinnerID: &winner, GuessSeconds: &seconds},
+			},
+			Guesses: []GuessRecord{
+				{GameID: id, Round: 1, PlayerID: 2, Text: "anjing", Result: GuessWrong, Seconds: 4},
+				{GameID: id, Round: 1, PlayerID: 2, Text: "kucing", Result: GuessCorrect, Seconds: seconds},
+			},
+		}
+		if err := s.SaveGameRecord(detail); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SaveGameRecord(&GameDetail{Game: GameRecord{ID: "g1"}}); err == nil {
+		t.Error("saving the same game twice succeeded")
+	}
+
+	recent, _ := s.GetRecentGames(-100, 2)
+	if len(recent) != 2 || recent[0].ID != "g3" || recent[1].ID != "g2" {
+		t.Errorf("recent games = %+v, want g3 then g2", recent)
+	}
+	if other, _ := s.GetRecentGames(-200, 5); len(other) != 0 {
+		t.Errorf("recent games of another chat = %+v", other)
+	}
+
+	detail, err := s.GetGameDetail("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Players) != 2 || detail.Players[0].PlayerID != 2 || len(detail.Rounds) != 1 || len(detail.Guesses) != 2 {
+		t.Errorf("game detail = %+v", detail)
+	}
+	if _, err := s.GetGameDetail("missing"); err == nil {
+		t.Error("GetGameDetail of a missing game succeeded")
+	}
+
+	budi, _ := s.GetPlayerHistoryStats(2)
+	if budi.Games != 3 || budi.TotalPoints != 45 || budi.BestPoints != 20 || budi.CorrectGuesses != 3 || budi.WrongGuesses != 3 || budi.AvgGuessSeconds != seconds {
+		t.Errorf("guesser stats = %+v", budi)
+	}
+	ani, _ := s.GetPlayerHistoryStats(1)
+	if ani.Games != 3 || ani.CluesGiven != 3 || ani.CluesSolved != 3 {
+		t.Errorf("clue giver stats = %+v", ani)
+	}
+}
+
+func TestLocalStoreSavesOnlyChanges(t *testing.T) {
+	s, path := newTestStore(t)
+	mustCreatePlayer(t, s, 1, "Ani")
+	s.SaveGameSnapshot(SnapshotGroup, -100, []byte(`{"round":1}`))
+	s.SetPlayerPMReachable(1, true)
+	s.GetOrCreateChat(-100, "group")
+
+	// Tanpa berkas, penulisan berikutnya akan terlihat dari munculnya berkas lagi.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	mustCreatePlayer(t, s, 1, "Ani")
+	s.GetOrCreateChat(-100, "group")
+	s.SaveGameSnapshot(SnapshotGroup, -100, []byte(`{"round":1}`))
+	s.SetPlayerPMReachable(1, true)
+	s.UpdatePlayerFastestGuess(1, -1)
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("store file was written without any change (stat error %v)", err)
+	}
+
+	s.AddPoints(1, 5, LedgerGamePayout, "")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("store file was not written after a change: %v", err)
+	}
+}
@@ -15,11 +15,22 @@ type Player struct {
 
 	GamesPlayed        int       `json:"games_played"`
 	GamesWon           int       `json:"games_won"`
+	// GamesCounted adalah jumlah permainan yang hasilnya dihitung, dasar win rate.
+	GamesCounted       int       `json:"games_counted"`
 	FastestGuess       float64   `json:"fastest_guess"`
 	ClueGivenCount     int       `json:"clue_given_count"`
 	ClueSuccessCount   int       `json:"clue_success_count"`
 	WordsGuessedCount  int       `json:"words_guessed_count"`
+	MissedTurnsCount   int       `json:"missed_turns_count"`
 	EquippedBadgeID    *int      `json:"equipped_badge_id"`
+	CanReceivePM       bool      `json:"can_receive_pm"`
+	WinStreak          int       `json:"win_streak"`
+	BestWinStreak      int       `json:"best_win_streak"`
+	// Rating Glicko pemain; nilai nol dianggap rating awal (lihat SkillRating).
+	Rating             float64    `json:"rating,omitempty"`
+	RatingDeviation    float64    `json:"rating_deviation,omitempty"`
+	RatedGames         int        `json:"rated_games"`
+	RatedAt            *time.Time `json:"rated_at,omitempty"`
 }
 
 type Badge struct {
@@ -34,6 +45,7 @@ type Badge struct {
 
 // TANDA: Struct PlayerBadge ditambahkan
 type PlayerBadge struct {
-	PlayerID int64 `json:"player_id"`
-	BadgeID  int   `json:"badge_id"`
+	PlayerID  int64      `json:"player_id"`
+	BadgeID   int        `json:"badge_id"`
+	AwardedAt *time.Time `json:"awarded_at,omitempty"` // nil untuk lencana lama
 }
\ No newline at end of file
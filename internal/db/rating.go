@@ -0,0 +1,77 @@
+package db
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"detektif-kata-bot/internal/rating"
+)
+
+// RatingUpdate adalah rating baru seorang pemain setelah permainan berperingkat.
+type RatingUpdate struct {
+	PlayerID int64
+	Rating   rating.Rating
+}
+
+// SkillRating mengembalikan rating pemain, atau rating awal jika belum pernah dinilai.
+func (p Player) SkillRating() rating.Rating {
+	if p.Rating == 0 || p.RatingDeviation == 0 {
+		return rating.New()
+	}
+	return rating.Rating{Value: p.Rating, Deviation: p.RatingDeviation, RatedAt: p.RatedAt}
+}
+
+// UpdatePlayerRatings menyimpan rating baru semua pemain sebuah permainan
+// dalam satu RPC dan menambah jumlah permainan berperingkat mereka.
+func (c *Client) UpdatePlayerRatings(updates []RatingUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, len(updates))
+	for i, u := range updates {
+		rows[i] = map[string]interface{}{
+			"player_id": u.PlayerID,
+			"rating":    u.Rating.Value,
+			"deviation": u.Rating.Deviation,
+		}
+	}
+	var updated *int
+	params := map[string]interface{}{"p_updates": rows}
+	if err := c.DB.Rpc("record_player_ratings", params).Execute(&updated); err != nil {
+		log.Printf("Error updating ratings of %d players: %v", len(updates), err)
+		return err
+	}
+	return nil
+}
+
+// GetTopPlayersByRating mengambil pemain berperingkat dengan rating tertinggi.
+// Urutannya memakai batas bawah rating setelah deviasi dinaikkan sesuai lama
+// tidak bermain, jadi pemain yang lama absen perlahan turun.
+func (c *Client) GetTopPlayersByRating(limit int) ([]Player, error) {
+	var results []Player
+	err := c.DB.From("players").Select("*").Gt("rated_games", "0").Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching players for rating leaderboard: %v", err)
+		return nil, err
+	}
+	sortByRating(results, time.Now())
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func sortByRating(players []Player, now time.Time) {
+	score := make(map[int64]float64, len(players))
+	for _, p := range players {
+		score[p.TelegramUserID] = p.SkillRating().Decayed(now).Conservative()
+	}
+	sort.Slice(players, func(i, j int) bool {
+		a, b := score[players[i].TelegramUserID], score[players[j].TelegramUserID]
+		if a != b {
+			return a > b
+		}
+		return players[i].TelegramUserID < players[j].TelegramUserID
+	})
+}
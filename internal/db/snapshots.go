@@ -0,0 +1,60 @@
+package db
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Jenis snapshot yang disimpan di tabel game_snapshots.
+const (
+	SnapshotGroup = "group"
+	SnapshotSolo  = "solo"
+)
+
+// GameSnapshot menyimpan state permainan yang sedang berjalan dalam bentuk JSON.
+type GameSnapshot struct {
+	Kind      string          `json:"kind"`
+	ChatID    int64           `json:"chat_id"`
+	State     json.RawMessage `json:"state"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// SaveGameSnapshot menyimpan (atau menimpa) snapshot permainan untuk sebuah chat.
+func (c *Client) SaveGameSnapshot(kind string, chatID int64, state []byte) error {
+	snapshot := GameSnapshot{
+		Kind:      kind,
+		ChatID:    chatID,
+		State:     state,
+		UpdatedAt: time.Now(),
+	}
+
+	var results []GameSnapshot
+	err := c.DB.From("game_snapshots").Upsert(snapshot).Execute(&results)
+	if err != nil {
+		log.Printf("Error saving %s game snapshot for chat %d: %v", kind, chatID, err)
+	}
+	return err
+}
+
+// DeleteGameSnapshot menghapus snapshot permainan yang sudah selesai.
+func (c *Client) DeleteGameSnapshot(kind string, chatID int64) error {
+	var results []GameSnapshot
+	err := c.DB.From("game_snapshots").Delete().Eq("kind", kind).Eq("chat_id", strconv.FormatInt(chatID, 10)).Execute(&results)
+	if err != nil {
+		log.Printf("Error deleting %s game snapshot for chat %d: %v", kind, chatID, err)
+	}
+	return err
+}
+
+// GetGameSnapshots mengambil semua snapshot permainan yang belum selesai.
+func (c *Client) GetGameSnapshots() ([]GameSnapshot, error) {
+	var results []GameSnapshot
+	err := c.DB.From("game_snapshots").Select("*").Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching game snapshots: %v", err)
+		return nil, err
+	}
+	return results, nil
+}
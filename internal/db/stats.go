@@ -3,58 +3,54 @@
 package db
 
 import (
+	"fmt"
 	"log"
 	"strconv"
 )
 
-// IncrementPlayerStats menambah nilai statistik pemain.
+// IncrementPlayerStats menambah nilai statistik pemain secara atomik di server.
 // Contoh: field = "games_played", value = 1
 func (c *Client) IncrementPlayerStats(playerID int64, field string, value int) error {
-	var results []Player
-	err := c.DB.From("players").Select(field).Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(&results)
-	if err != nil || len(results) == 0 {
-		log.Printf("Error fetching player for stats update: %v", err)
+	var newVal *int
+	params := map[string]interface{}{"p_player_id": playerID, "p_field": field, "p_value": value}
+	err := c.DB.Rpc("increment_player_stat", params).Execute(&newVal)
+	if err != nil {
+		log.Printf("Error updating stats for player %d: %v", playerID, err)
 		return err
 	}
-
-	var currentVal int
-	switch field {
-	case "games_played":
-		currentVal = results[0].GamesPlayed
-	case "games_won":
-		currentVal = results[0].GamesWon
-	case "clue_given_count":
-		currentVal = results[0].ClueGivenCount
-	case "clue_success_count":
-		currentVal = results[0].ClueSuccessCount
-	case "words_guessed_count":
-		currentVal = results[0].WordsGuessedCount
+	if newVal == nil {
+		return ErrPlayerNotFound
 	}
-	
-	newVal := currentVal + value
-	err = c.DB.From("players").Update(map[string]interface{}{field: newVal}).Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(nil)
+	return nil
+}
+
+// UpdatePlayerFastestGuess memperbarui rekor tebakan tercepat pemain.
+// Perbandingan dengan rekor lama dilakukan di server.
+func (c *Client) UpdatePlayerFastestGuess(playerID int64, newTime float64) error {
+	var updated *bool
+	params := map[string]interface{}{"p_player_id": playerID, "p_seconds": newTime}
+	err := c.DB.Rpc("record_fastest_guess", params).Execute(&updated)
 	if err != nil {
-		log.Printf("Error updating stats for player %d: %v", playerID, err)
+		log.Printf("Error updating fastest guess for player %d: %v", playerID, err)
 	}
 	return err
 }
 
-// UpdatePlayerFastestGuess memperbarui rekor tebakan tercepat pemain.
-func (c *Client) UpdatePlayerFastestGuess(playerID int64, newTime float64) error {
-	var results []Player
-	err := c.DB.From("players").Select("fastest_guess").Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(&results)
-	if err != nil || len(results) == 0 {
+// RecordGameResult mencatat hasil permainan yang kemenangannya dihitung:
+// menambah games_counted, games_won jika menang, dan memperbarui rentetan
+// menang pemain.
+func (c *Client) RecordGameResult(playerID int64, won bool) error {
+	params := map[string]interface{}{"p_player_id": playerID, "p_won": won}
+	var streak *int
+	err := c.DB.Rpc("record_game_result", params).Execute(&streak)
+	if err != nil {
+		log.Printf("Error recording game result for player %d: %v", playerID, err)
 		return err
 	}
-	
-	// Perbarui hanya jika rekor baru lebih cepat, atau jika belum ada rekor (-1)
-	if results[0].FastestGuess == -1 || newTime < results[0].FastestGuess {
-		err = c.DB.From("players").Update(map[string]interface{}{"fastest_guess": newTime}).Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(nil)
-		if err != nil {
-			log.Printf("Error updating fastest guess for player %d: %v", playerID, err)
-		}
+	if streak == nil {
+		return ErrPlayerNotFound
 	}
-	return err
+	return nil
 }
 
 // SetEquippedBadge menetapkan lencana yang dipakai oleh pemain.
@@ -66,6 +62,15 @@ func (c *Client) SetEquippedBadge(playerID int64, badgeID int) error {
 	return err
 }
 
+// SetPlayerPMReachable mencatat apakah bot bisa mengirim pesan pribadi ke pemain.
+func (c *Client) SetPlayerPMReachable(playerID int64, reachable bool) error {
+	err := c.DB.From("players").Update(map[string]interface{}{"can_receive_pm": reachable}).Eq("telegram_user_id", strconv.FormatInt(playerID, 10)).Execute(nil)
+	if err != nil {
+		log.Printf("Error updating PM reachability for player %d: %v", playerID, err)
+	}
+	return err
+}
+
 // GetPlayerByID mengambil data lengkap pemain, termasuk statistik baru.
 func (c *Client) GetPlayerByID(playerID int64) (*Player, error) {
 	var results []Player
@@ -74,4 +79,19 @@ func (c *Client) GetPlayerByID(playerID int64) (*Player, error) {
 		return nil, err
 	}
 	return &results[0], nil
-}
\ No newline at end of file
+}
+
+// GetPlayersByIDs mengambil data banyak pemain sekaligus dalam satu query.
+func (c *Client) GetPlayersByIDs(playerIDs []int64) ([]Player, error) {
+	if len(playerIDs) == 0 {
+		return []Player{}, nil
+	}
+	var results []Player
+	filter := fmt.Sprintf("(%s)", int64SliceToCommaSeparated(playerIDs))
+	err := c.DB.From("players").Select("*").Filter("telegram_user_id", "in", filter).Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching %d players: %v", len(playerIDs), err)
+		return nil, err
+	}
+	return results, nil
+}
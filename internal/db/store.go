@@ -0,0 +1,69 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"detektif-kata-bot/internal/config"
+)
+
+var (
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrInsufficientPoints = errors.New("insufficient points")
+)
+
+// Store adalah semua operasi penyimpanan yang dipakai bot. Client (Supabase)
+// dan LocalStore (berkas JSON lokal) sama-sama memenuhinya.
+type Store interface {
+	// Pemain dan statistik
+	GetOrCreatePlayer(tgUser *config.User) (*Player, error)
+	GetPlayerByID(playerID int64) (*Player, error)
+	GetPlayersByIDs(playerIDs []int64) ([]Player, error)
+	GetTopPlayers(limit int) ([]Player, error)
+	GetTopPlayersByWins(limit int) ([]Player, error)
+	GetTopPlayersByRating(limit int) ([]Player, error)
+	UpdatePlayerRatings(updates []RatingUpdate) error
+	AddPoints(playerID int64, pointsToAdd int, reason, ref string) error
+	SpendPoints(playerID int64, amount int, reason, ref string) (int, error)
+	GetPointsLedger(playerID int64, limit, offset int) ([]LedgerEntry, error)
+	GetLedgerTotals(playerID int64) (*LedgerTotals, error)
+	IncrementPlayerStats(playerID int64, field string, value int) error
+	RecordGameResult(playerID int64, won bool) error
+	UpdatePlayerFastestGuess(playerID int64, newTime float64) error
+	SetEquippedBadge(playerID int64, badgeID int) error
+	SetPlayerPMReachable(playerID int64, reachable bool) error
+
+	// Lencana
+	GetAllBadges() ([]Badge, error)
+	GetAchievementBadges() ([]Badge, error)
+	GetPurchasableBadges() ([]Badge, error)
+	GetBadgeByID(badgeID int) (*Badge, error)
+	GetPlayerBadges(playerID int64) ([]Badge, error)
+	GetPlayerBadgeLinks(playerIDs []int64) ([]PlayerBadge, error)
+	AwardBadgeToPlayer(playerID int64, badgeID int) error
+
+	// Chat dan pengaturannya
+	GetOrCreateChat(chatID int64, chatType string) error
+	GetAllChatsByType(chatType string) ([]int64, error)
+	GetChatSettings(chatID int64) (*ChatSettings, error)
+	SaveChatSettings(settings *ChatSettings) error
+
+	// Snapshot permainan
+	SaveGameSnapshot(kind string, chatID int64, state []byte) error
+	DeleteGameSnapshot(kind string, chatID int64) error
+	GetGameSnapshots() ([]GameSnapshot, error)
+
+	// Riwayat permainan
+	SaveGameRecord(detail *GameDetail) error
+	GetRecentGames(chatID int64, limit int) ([]GameRecord, error)
+	GetGameDetail(gameID string) (*GameDetail, error)
+	GetPlayerHistoryStats(playerID int64) (*PlayerHistoryStats, error)
+
+	// Katalog dan riwayat kata
+	GetWords() ([]Word, error)
+	RecordWordUsage(chatID int64, word string, usedAt time.Time) error
+	GetWordHistory(chatID int64, limit int) ([]WordUsage, error)
+	RecordWordReroll(chatID int64, playerID int64, word string) error
+}
+
+var _ Store = (*Client)(nil)
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// WordUsage mencatat kapan sebuah kata terakhir dipakai di sebuah chat.
+type WordUsage struct {
+	ChatID int64     `json:"chat_id"`
+	Word   string    `json:"word"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+// RecordWordUsage menyimpan (atau memperbarui) waktu pemakaian kata di sebuah chat.
+func (c *Client) RecordWordUsage(chatID int64, word string, usedAt time.Time) error {
+	usage := WordUsage{ChatID: chatID, Word: word, UsedAt: usedAt}
+
+	var results []WordUsage
+	err := c.DB.From("word_history").Upsert(usage).Execute(&results)
+	if err != nil {
+		log.Printf("Error recording word usage for chat %d: %v", chatID, err)
+	}
+	return err
+}
+
+// GetWordHistory mengambil riwayat pemakaian kata di sebuah chat, yang terbaru lebih dulu.
+func (c *Client) GetWordHistory(chatID int64, limit int) ([]WordUsage, error) {
+	var results []WordUsage
+	err := c.DB.From("word_history").Select("*").Eq("chat_id", strconv.FormatInt(chatID, 10)).Execute(&results)
+	if err != nil {
+		log.Printf("Error fetching word history for chat %d: %v", chatID, err)
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].UsedAt.After(results[j].UsedAt)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// WordReroll mencatat kata yang diganti oleh Pemberi Petunjuk, sebagai bahan
+// statistik tingkat kesulitan kata.
+type WordReroll struct {
+	ChatID     int64     `json:"chat_id"`
+	PlayerID   int64     `json:"player_id"`
+	Word       string    `json:"word"`
+	RerolledAt time.Time `json:"rerolled_at"`
+}
+
+// RecordWordReroll menyimpan satu kejadian ganti kata.
+func (c *Client) RecordWordReroll(chatID int64, playerID int64, word string) error {
+	reroll := WordReroll{ChatID: chatID, PlayerID: playerID, Word: word, RerolledAt: time.Now()}
+
+	var results []WordReroll
+	err := c.DB.From("word_rerolls").Insert(reroll).Execute(&results)
+	if err != nil {
+		log.Printf("Error recording word reroll for chat %d: %v", chatID, err)
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"log"
+)
+
+// Word adalah satu entri katalog kata rahasia.
+type Word struct {
+	ID         int      `json:"id,omitempty"`
+	Word       string   `json:"word"`
+	Category   string   `json:"category"`
+	Difficulty int      `json:"difficulty"`
+	Language   string   `json:"language"`
+	Aliases    []string `json:"aliases"`
+	Taboo      []string `json:"taboo"`
+	Hints      []string `json:"hints"`
+}
+
+// GetWords mengambil seluruh katalog kata dari database.
+func (c *Client) GetWords() ([]Word, error) {
+	var words []Word
+	err := c.DB.From("words").Select("*").Execute(&words)
+	if err != nil {
+		log.Printf("Error fetching word catalog: %v", err)
+		return nil, err
+	}
+	return words, nil
+}
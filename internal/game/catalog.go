@@ -0,0 +1,170 @@
+package game
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// DefaultCategory dipakai untuk kata bawaan yang tidak punya kategori.
+const DefaultCategory = "umum"
+
+// catalogTTL menentukan seberapa lama katalog di-cache sebelum dimuat ulang.
+const catalogTTL = 10 * time.Minute
+
+// Catalog menyimpan katalog kata dari database di memori. Jika database
+// kosong atau gagal dimuat, WordList dan SoloWordList dipakai sebagai cadangan.
+type Catalog struct {
+	load     func() ([]db.Word, error)
+	language string
+
+	mu       sync.RWMutex
+	words    []db.Word
+	loadedAt time.Time
+}
+
+func NewCatalog(load func() ([]db.Word, error), language string) *Catalog {
+	return &Catalog{load: load, language: language}
+}
+
+// Words mengembalikan semua kata dalam bahasa katalog, difilter per kategori
+// jika category tidak kosong.
+func (c *Catalog) Words(category string) []db.Word {
+	all := c.all()
+	words := make([]db.Word, 0, len(all))
+	for _, w := range all {
+		if category != "" && !strings.EqualFold(w.Category, category) {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+// PickSolo memilih kata acak yang punya petunjuk untuk mode solo.
+func (c *Catalog) PickSolo() db.Word {
+	var words []db.Word
+	for _, w := range c.all() {
+		if len(w.Hints) > 0 {
+			words = append(words, w)
+		}
+	}
+	if len(words) == 0 {
+		words = soloSeed()
+	}
+	return words[rand.Intn(len(words))]
+}
+
+// WithTaboo menyaring kata yang punya daftar kata terlarang untuk mode tabu.
+func WithTaboo(words []db.Word) []db.Word {
+	var result []db.Word
+	for _, w := range words {
+		if len(w.Taboo) > 0 {
+			result = append(result, w)
+		}
+	}
+	return result
+}
+
+// HasCategory memeriksa apakah kategori tersebut punya setidaknya satu kata.
+func (c *Catalog) HasCategory(category string) bool {
+	return len(c.Words(category)) > 0
+}
+
+// Categories mengembalikan daftar kategori yang tersedia, urut abjad.
+func (c *Catalog) Categories() []string {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, w := range c.all() {
+		category := strings.ToLower(w.Category)
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+func (c *Catalog) all() []db.Word {
+	c.mu.RLock()
+	words, fresh := c.words, time.Since(c.loadedAt) < catalogTTL
+	c.mu.RUnlock()
+	if fresh {
+		return words
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.loadedAt) < catalogTTL {
+		return c.words
+	}
+
+	loaded, err := c.load()
+	if err != nil {
+		log.Printf("Failed to load word catalog, using fallback words: %v", err)
+	}
+	words = make([]db.Word, 0, len(loaded))
+	for _, w := range loaded {
+		if w.Word == "" || (c.language != "" && w.Language != "" && w.Language != c.language) {
+			continue
+		}
+		if w.Category == "" {
+			w.Category = DefaultCategory
+		}
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		if len(c.words) > 0 && err != nil {
+			// Pertahankan katalog lama jika database sedang bermasalah.
+			words = c.words
+		} else {
+			words = SeedWords()
+		}
+	}
+
+	c.words = words
+	c.loadedAt = time.Now()
+	return words
+}
+
+// SeedWords membangun katalog cadangan dari WordList dan SoloWordList.
+func SeedWords() []db.Word {
+	hints := make(map[string][]string)
+	for _, wd := range SoloWordList {
+		hints[wd.Word] = wd.Hints
+	}
+
+	words := make([]db.Word, 0, len(WordList)+len(SoloWordList))
+	for _, w := range WordList {
+		words = append(words, db.Word{
+			Word:       w,
+			Category:   DefaultCategory,
+			Difficulty: 1,
+			Language:   "id",
+			Taboo:      TabooList[w],
+			Hints:      hints[w],
+		})
+		delete(hints, w)
+	}
+	// Kata solo yang tidak ada di WordList tetap ikut sebagai cadangan.
+	for _, wd := range SoloWordList {
+		if _, ok := hints[wd.Word]; ok {
+			words = append(words, db.Word{Word: wd.Word, Category: DefaultCategory, Difficulty: 1, Language: "id", Hints: wd.Hints})
+		}
+	}
+	return words
+}
+
+func soloSeed() []db.Word {
+	words := make([]db.Word, 0, len(SoloWordList))
+	for _, wd := range SoloWordList {
+		words = append(words, db.Word{Word: wd.Word, Category: DefaultCategory, Language: "id", Hints: wd.Hints})
+	}
+	return words
+}
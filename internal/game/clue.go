@@ -0,0 +1,132 @@
+package game
+
+import (
+	"strings"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// minStemLength mencegah kata dasar yang terlalu pendek dianggap sama.
+const minStemLength = 4
+
+// prefixRules berisi awalan bahasa Indonesia dan huruf awal kata dasar yang
+// mungkin luluh, misalnya "memukul" -> "pukul" dan "menyapu" -> "sapu".
+var prefixRules = []struct {
+	prefix  string
+	restore []string
+}{
+	{"meng", []string{"", "k"}},
+	{"meny", []string{"s"}},
+	{"mem", []string{"", "p"}},
+	{"men", []string{"", "t"}},
+	{"me", []string{""}},
+	{"peng", []string{"", "k"}},
+	{"peny", []string{"s"}},
+	{"pem", []string{"", "p"}},
+	{"pen", []string{"", "t"}},
+	{"per", []string{""}},
+	{"pe", []string{""}},
+	{"ber", []string{""}},
+	{"be", []string{""}},
+	{"ter", []string{""}},
+	{"di", []string{""}},
+	{"ke", []string{""}},
+	{"se", []string{""}},
+}
+
+var (
+	particleSuffixes = []string{"lah", "kah", "pun", "nya"}
+	derivSuffixes    = []string{"kan", "an", "i"}
+)
+
+// ValidateClue memeriksa apakah petunjuk membocorkan kata rahasia atau salah
+// satu aliasnya: sama persis, mengandung atau menjadi bagiannya, atau punya
+// kata dasar yang sama setelah imbuhan dibuang. Pemeriksaan "mengandung"
+// hanya memakai kata utuh; kata dasar hasil pemotongan bisa sangat pendek
+// ("perawat" -> "awat") sehingga harus sama persis.
+func ValidateClue(clue string, word db.Word) error {
+	normalized := Normalize(clue)
+	clueStems := stems(normalized)
+
+	for _, answer := range append([]string{word.Word}, word.Aliases...) {
+		target := Normalize(answer)
+		if target == "" {
+			continue
+		}
+		if normalized == target {
+			return ErrClueIsSecretWord
+		}
+		if strings.Contains(normalized, target) ||
+			(len(normalized) >= minStemLength && strings.Contains(target, normalized)) {
+			return ErrClueContainsSecretWord
+		}
+
+		for _, answerStem := range stems(target) {
+			for _, clueStem := range clueStems {
+				if clueStem == answerStem {
+					return ErrClueSharesStem
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// IsTaboo memeriksa apakah petunjuk termasuk kata terlarang, termasuk bentuk
+// berimbuhan dari kata terlarang tersebut.
+func IsTaboo(clue string, taboo []string) bool {
+	clueStems := stems(Normalize(clue))
+	for _, t := range taboo {
+		for _, tabooStem := range stems(Normalize(t)) {
+			for _, clueStem := range clueStems {
+				if clueStem == tabooStem {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// stems mengembalikan semua kemungkinan kata dasar dari sebuah kata yang sudah
+// dinormalisasi, dengan membuang partikel, akhiran dan hingga dua lapis awalan.
+// Kata itu sendiri ikut dikembalikan.
+func stems(word string) []string {
+	seen := map[string]bool{word: true}
+	result := []string{word}
+	add := func(s string) {
+		if len([]rune(s)) >= minStemLength && !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+
+	for _, suffix := range particleSuffixes {
+		if strings.HasSuffix(word, suffix) {
+			add(strings.TrimSuffix(word, suffix))
+		}
+	}
+	for _, w := range append([]string(nil), result...) {
+		for _, suffix := range derivSuffixes {
+			if strings.HasSuffix(w, suffix) {
+				add(strings.TrimSuffix(w, suffix))
+			}
+		}
+	}
+	// Dua putaran untuk awalan bertumpuk seperti "memper-" dan "keber-".
+	for round := 0; round < 2; round++ {
+		for _, w := range append([]string(nil), result...) {
+			for _, rule := range prefixRules {
+				if !strings.HasPrefix(w, rule.prefix) {
+					continue
+				}
+				rest := strings.TrimPrefix(w, rule.prefix)
+				for _, r := range rule.restore {
+					add(r + rest)
+				}
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"errors"
+	"testing"
+
+	"detektif-kata-bot/internal/db"
+)
+
+func TestValidateClue(t *testing.T) {
+	tests := []struct {
+		name    string
+		clue    string
+		word    db.Word
+		wantErr error
+	}{
+		// Membocorkan kata rahasia
+		{"same word", "Kucing", db.Word{Word: "kucing"}, ErrClueIsSecretWord},
+		{"same alias", "kitty", db.Word{Word: "kucing", Aliases: []string{"kitty"}}, ErrClueIsSecretWord},
+		{"contains word", "kucingku", db.Word{Word: "kucing"}, ErrClueContainsSecretWord},
+		{"part of word", "rumah", db.Word{Word: "rumah sakit"}, ErrClueContainsSecretWord},
+		{"prefix me- with melted p", "memukul", db.Word{Word: "pukul"}, ErrClueSharesStem},
+		{"prefix meny- with melted s", "menyapu", db.Word{Word: "sapu"}, ErrClueSharesStem},
+		{"prefix men- with melted t", "menulis", db.Word{Word: "tulis"}, ErrClueSharesStem},
+		{"prefix and suffix", "bermainan", db.Word{Word: "permainan"}, ErrClueSharesStem},
+		{"suffix -kan", "tuliskan", db.Word{Word: "menulis"}, ErrClueSharesStem},
+		{"particle -nya", "sapunya", db.Word{Word: "menyapu"}, ErrClueSharesStem},
+		{"stacked prefixes", "mempersatukan", db.Word{Word: "bersatu"}, ErrClueSharesStem},
+
+		// Tidak berhubungan dengan kata rahasia
+		{"stem is a substring only", "kawat", db.Word{Word: "perawat"}, nil},
+		{"shared stem inside longer stem", "gawat", db.Word{Word: "perawat"}, nil},
+		{"short stem inside clue", "klarinet", db.Word{Word: "pelari"}, nil},
+		{"unrelated", "meong", db.Word{Word: "kucing"}, nil},
+		{"short clue inside word", "kuc", db.Word{Word: "kucing"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateClue(tt.clue, tt.word); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateClue(%q, %q) = %v, want %v", tt.clue, tt.word.Word, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsTaboo(t *testing.T) {
+	taboo := []string{"hewan", "meong"}
+	tests := []struct {
+		clue string
+		want bool
+	}{
+		{"hewan", true},
+		{"hewannya", true},
+		{"mengeong", false},
+		{"binatang", false},
+	}
+	for _, tt := range tests {
+		if got := IsTaboo(tt.clue, taboo); got != tt.want {
+			t.Errorf("IsTaboo(%q) = %v, want %v", tt.clue, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,653 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// Batas dan jeda permainan grup yang tidak bisa diatur per grup.
+const (
+	MinPlayers = 2
+	StartDelay = 2 * time.Second
+	RoundBreak = 4 * time.Second
+)
+
+var (
+	ErrGameNotActive          = errors.New("game is not active")
+	ErrLobbyClosed            = errors.New("lobby is closed")
+	ErrAlreadyJoined          = errors.New("player already joined")
+	ErrGameFull               = errors.New("game is full")
+	ErrNotHost                = errors.New("only the host can do this")
+	ErrNotEnoughPlayers       = errors.New("not enough players")
+	ErrNotClueGiver           = errors.New("player is not the clue giver")
+	ErrClueNotOneWord         = errors.New("clue must be a single word")
+	ErrClueIsSecretWord       = errors.New("clue is the secret word")
+	ErrClueContainsSecretWord = errors.New("clue contains or is part of the secret word")
+	ErrClueSharesStem         = errors.New("clue shares a stem with the secret word")
+	ErrClueIsTaboo            = errors.New("clue is a taboo word")
+	ErrNoRerollsLeft          = errors.New("no word rerolls left")
+	ErrNotParticipant         = errors.New("player is not a participant")
+	ErrClueGiverGuess         = errors.New("clue giver cannot guess")
+	ErrUnexpectedEvent        = errors.New("event is not valid in the current state")
+)
+
+// Engine menjalankan aturan permainan grup tanpa bergantung pada Telegram
+// atau database. Setiap event mengubah State dan menghasilkan daftar efek
+// yang harus dijalankan oleh pemanggil.
+type Engine struct {
+	State    *GameState
+	PickWord func(s *GameState) db.Word
+	Now      func() time.Time
+	Shuffle  func(n int, swap func(i, j int))
+}
+
+func NewEngine(state *GameState) *Engine {
+	return &Engine{
+		State:    state,
+		PickWord: RandomWord,
+		Now:      time.Now,
+		Shuffle:  rand.Shuffle,
+	}
+}
+
+// RandomWord memilih kata acak dari katalog cadangan, tanpa memperhatikan kategori.
+func RandomWord(s *GameState) db.Word {
+	words := SeedWords()
+	return words[rand.Intn(len(words))]
+}
+
+// Handle menerapkan satu event ke State. Error berarti event ditolak dan
+// State tidak berubah.
+func (e *Engine) Handle(ev Event) ([]Effect, error) {
+	if !e.State.IsActive {
+		return nil, ErrGameNotActive
+	}
+
+	switch ev := ev.(type) {
+	case OpenLobbyEvent:
+		if e.State.Status != StatusLobby {
+			return nil, ErrUnexpectedEvent
+		}
+		return e.lobbyActivity(), nil
+	case JoinEvent:
+		return e.join(ev)
+	case LeaveEvent:
+		return e.leave(ev)
+	case StartEvent:
+		return e.start(ev)
+	case ClueEvent:
+		return e.submitClue(ev)
+	case GuessEvent:
+		return e.guess(ev)
+	case RerollEvent:
+		return e.reroll(ev)
+	case SkipTurnEvent:
+		if e.State.Status != StatusWaitingForClue {
+			return nil, ErrUnexpectedEvent
+		}
+		return e.skipTurn(ev.Reason), nil
+	case TimeoutEvent:
+		return e.timeout(ev), nil
+	case EndEvent:
+		if ev.PlayerID != 0 && ev.PlayerID != e.State.Host.TelegramUserID {
+			return nil, ErrNotHost
+		}
+		reason := EndReasonCompleted
+		if ev.PlayerID != 0 {
+			reason = EndReasonHost
+		}
+		return e.finish(reason), nil
+	}
+	return nil, ErrUnexpectedEvent
+}
+
+func (e *Engine) join(ev JoinEvent) ([]Effect, error) {
+	s := e.State
+	id := ev.Player.TelegramUserID
+	if _, joined := s.Players[id]; joined {
+		return nil, ErrAlreadyJoined
+	}
+	if s.Settings.MaxPlayers > 0 && len(s.Players) >= s.Settings.MaxPlayers {
+		return nil, ErrGameFull
+	}
+	if s.Status == StatusLobby {
+		s.Players[id] = ev.Player
+		return append(e.lobbyActivity(), LobbyChanged{}), nil
+	}
+
+	// Pemain yang kembali memakai entri lamanya supaya skor sebelumnya tetap terhitung.
+	player := ev.Player
+	if prev, ok := s.LeftPlayers[id]; ok {
+		player = prev
+		delete(s.LeftPlayers, id)
+	}
+	s.Players[id] = player
+	s.addToTurnOrder(player)
+	return []Effect{PlayerJoined{Player: player}}, nil
+}
+
+// leave mengeluarkan pemain dari lobi atau permainan. Skor sesi pemain yang
+// keluar di tengah permainan tetap disimpan dan ikut dihitung di akhir.
+func (e *Engine) leave(ev LeaveEvent) ([]Effect, error) {
+	s := e.State
+	player, ok := s.Players[ev.PlayerID]
+	if !ok {
+		return nil, ErrNotParticipant
+	}
+	delete(s.Players, ev.PlayerID)
+	delete(s.MissedTurns, ev.PlayerID)
+
+	var effects []Effect
+	if s.Status == StatusLobby && len(s.Players) > 0 {
+		effects = append(e.lobbyActivity(), LobbyChanged{})
+	} else if s.Status != StatusLobby {
+		s.LeftPlayers[ev.PlayerID] = player
+		s.removeFromTurnOrder(ev.PlayerID)
+		effects = append(effects, PlayerLeft{Player: player, Points: s.SessionScores[ev.PlayerID]})
+	}
+
+	if len(s.Players) == 0 || (s.Status != StatusLobby && len(s.Players) < MinPlayers) {
+		return append(effects, e.finish(EndReasonAbandoned)...), nil
+	}
+	if s.Host.TelegramUserID == ev.PlayerID {
+		s.Host = s.nextHost()
+		effects = append(effects, HostChanged{Host: s.Host})
+	}
+	// Giliran Pemberi Petunjuk yang keluar sebelum mengirim petunjuk langsung dilewati.
+	if s.Status == StatusWaitingForClue && s.ClueGiver.TelegramUserID == ev.PlayerID {
+		effects = append(effects,
+			e.stop(TimerClueReminder),
+			e.stop(TimerClueDeadline),
+			TurnSkipped{ClueGiver: player, Reason: SkipReasonLeft},
+		)
+		e.recordRound(db.RoundOutcomeSkipped, nil, 0)
+		effects = append(effects, e.endRound()...)
+	}
+	return effects, nil
+}
+
+func (e *Engine) start(ev StartEvent) ([]Effect, error) {
+	s := e.State
+	if s.Status != StatusLobby {
+		return nil, ErrUnexpectedEvent
+	}
+	if ev.PlayerID != s.Host.TelegramUserID {
+		return nil, ErrNotHost
+	}
+	if len(s.Players) < MinPlayers {
+		return nil, ErrNotEnoughPlayers
+	}
+	return e.begin(false), nil
+}
+
+// lobbyActivity memasang ulang batas waktu lobi setiap kali ada yang bergabung
+// atau keluar, dan memulai hitung mundur otomatis begitu pemain cukup.
+func (e *Engine) lobbyActivity() []Effect {
+	s := e.State
+	var effects []Effect
+	if s.Settings.LobbyExpiry > 0 {
+		effects = append(effects, e.arm(TimerLobbyExpiry, s.Settings.LobbyExpiry))
+	}
+	_, counting := s.Deadlines[TimerAutoStart]
+	switch {
+	case s.Settings.AutoStart > 0 && len(s.Players) >= MinPlayers && !counting:
+		effects = append(effects, e.arm(TimerAutoStart, s.Settings.AutoStart))
+	case len(s.Players) < MinPlayers && counting:
+		effects = append(effects, e.stop(TimerAutoStart))
+	}
+	return effects
+}
+
+// AutoStartIn mengembalikan sisa waktu hitung mundur mulai otomatis, jika sedang berjalan.
+func (e *Engine) AutoStartIn() (time.Duration, bool) {
+	d, ok := e.State.Deadlines[TimerAutoStart]
+	if !ok {
+		return 0, false
+	}
+	return d.At.Sub(e.Now()), true
+}
+
+// begin menutup lobi dan menyiapkan urutan giliran.
+func (e *Engine) begin(auto bool) []Effect {
+	s := e.State
+	s.TurnOrder = s.TurnOrder[:0]
+	s.CurrentTurnIndex = -1
+	for _, p := range s.Standings() {
+		s.TurnOrder = append(s.TurnOrder, p.Player)
+	}
+	e.Shuffle(len(s.TurnOrder), func(i, j int) {
+		s.TurnOrder[i], s.TurnOrder[j] = s.TurnOrder[j], s.TurnOrder[i]
+	})
+	s.Status = StatusIntermission
+	s.StartedAt = e.Now()
+	s.GameID = fmt.Sprintf("%d-%d", s.ChatID, s.StartedAt.UnixNano())
+
+	return []Effect{
+		e.stop(TimerAutoStart),
+		e.stop(TimerLobbyExpiry),
+		GameStarted{LobbyMessageID: s.LobbyMessageID, AutoStarted: auto},
+		e.arm(TimerNextRound, StartDelay),
+	}
+}
+
+func (e *Engine) startRound() []Effect {
+	s := e.State
+	if len(s.TurnOrder) == 0 {
+		return e.finish(EndReasonCompleted)
+	}
+	s.Round++
+	s.CurrentTurnIndex = (s.CurrentTurnIndex + 1) % len(s.TurnOrder)
+	s.ClueGiver = s.TurnOrder[s.CurrentTurnIndex]
+	s.Status = StatusWaitingForClue
+	s.Word = e.PickWord(s)
+	s.Clue = ""
+	s.ClueMessageID = 0
+	s.WrongGuesses = make([]string, 0)
+
+	effects := []Effect{
+		IncrementStat{PlayerID: s.ClueGiver.TelegramUserID, Field: "clue_given_count", Value: 1},
+		e.arm(TimerClueReminder, s.Settings.ClueReminder),
+	}
+	if s.Settings.ClueTimeout > 0 {
+		effects = append(effects, e.arm(TimerClueDeadline, s.Settings.ClueTimeout))
+	}
+	return append(effects, RoundStarted{
+		Round:       s.Round,
+		TotalRounds: s.TotalRounds,
+		ClueGiver:   s.ClueGiver,
+		SecretWord:  s.Word.Word,
+		Taboo:       s.taboo(),
+		RerollsLeft: s.rerollsLeft(s.ClueGiver.TelegramUserID),
+	})
+}
+
+// reroll mengganti kata rahasia Pemberi Petunjuk selama jatahnya masih ada.
+// Timer pengingat dan batas waktu petunjuk tetap berjalan.
+func (e *Engine) reroll(ev RerollEvent) ([]Effect, error) {
+	s := e.State
+	if s.Status != StatusWaitingForClue {
+		return nil, ErrUnexpectedEvent
+	}
+	if s.ClueGiver == nil || ev.PlayerID != s.ClueGiver.TelegramUserID {
+		return nil, ErrNotClueGiver
+	}
+	if s.rerollsLeft(ev.PlayerID) <= 0 {
+		return nil, ErrNoRerollsLeft
+	}
+
+	old := s.Word
+	// Coba beberapa kali supaya tidak mendapat kata yang sama lagi.
+	for i := 0; i < 5 && strings.EqualFold(s.Word.Word, old.Word); i++ {
+		s.Word = e.PickWord(s)
+	}
+	s.Rerolls[ev.PlayerID]++
+
+	return []Effect{WordRerolled{
+		Round:       s.Round,
+		ClueGiver:   s.ClueGiver,
+		OldWord:     old.Word,
+		NewWord:     s.Word.Word,
+		Taboo:       s.taboo(),
+		RerollsLeft: s.rerollsLeft(ev.PlayerID),
+	}}, nil
+}
+
+func (e *Engine) submitClue(ev ClueEvent) ([]Effect, error) {
+	s := e.State
+	if s.Status != StatusWaitingForClue {
+		return nil, ErrUnexpectedEvent
+	}
+	if s.ClueGiver == nil || ev.PlayerID != s.ClueGiver.TelegramUserID {
+		return nil, ErrNotClueGiver
+	}
+	if len(strings.Fields(ev.Text)) != 1 {
+		return nil, ErrClueNotOneWord
+	}
+	if err := ValidateClue(ev.Text, s.Word); err != nil {
+		return nil, err
+	}
+	if s.Mode == ModeTaboo && IsTaboo(ev.Text, s.Word.Taboo) {
+		return nil, ErrClueIsTaboo
+	}
+
+	s.Clue = strings.TrimSpace(ev.Text)
+	s.Status = StatusWaitingForGuesses
+	s.GuessingStartTime = e.Now()
+	delete(s.MissedTurns, ev.PlayerID)
+
+	effects := []Effect{
+		e.stop(TimerClueReminder),
+		e.stop(TimerClueDeadline),
+		ClueAccepted{Round: s.Round, ClueGiver: s.ClueGiver, Clue: s.Clue, GuessSeconds: s.guessSeconds()},
+		e.arm(TimerGuess, s.Settings.GuessDuration),
+	}
+	if warnAfter := s.Settings.GuessDuration - s.Settings.WarningBefore; s.Settings.WarningBefore > 0 && warnAfter > 0 {
+		effects = append(effects, e.arm(TimerGuessWarning, warnAfter))
+	}
+	return effects, nil
+}
+
+func (e *Engine) guess(ev GuessEvent) ([]Effect, error) {
+	s := e.State
+	if s.Status != StatusWaitingForGuesses {
+		return nil, ErrUnexpectedEvent
+	}
+	if _, ok := s.Players[ev.Player.TelegramUserID]; !ok {
+		return nil, ErrNotParticipant
+	}
+	if ev.Player.TelegramUserID == s.ClueGiver.TelegramUserID {
+		return nil, ErrClueGiverGuess
+	}
+
+	timeTaken := e.Now().Sub(s.GuessingStartTime).Seconds()
+	switch MatchGuess(ev.Text, s.Word) {
+	case MatchClose:
+		e.recordGuess(ev, db.GuessClose, timeTaken)
+		// Tebakan yang hampir benar tidak diumumkan supaya tidak membocorkan kata.
+		return []Effect{CloseGuess{Player: ev.Player}}, nil
+	case MatchNone:
+		e.recordGuess(ev, db.GuessWrong, timeTaken)
+		s.WrongGuesses = append(s.WrongGuesses, ev.Text)
+		wrong := make([]string, len(s.WrongGuesses))
+		copy(wrong, s.WrongGuesses)
+		return []Effect{WrongGuess{
+			Round:        s.Round,
+			ClueGiver:    s.ClueGiver,
+			Clue:         s.Clue,
+			GuessSeconds: s.guessSeconds(),
+			WrongGuesses: wrong,
+		}}, nil
+	}
+
+	e.recordGuess(ev, db.GuessCorrect, timeTaken)
+	e.recordRound(db.RoundOutcomeWon, ev.Player, timeTaken)
+	points, giverPoints := s.Settings.GuessPoints(timeTaken), 0
+	if s.Mode == ModeTaboo {
+		points, giverPoints = s.Settings.TabooPoints(timeTaken)
+	}
+	s.SessionScores[ev.Player.TelegramUserID] += points
+	if giverPoints > 0 {
+		s.SessionScores[s.ClueGiver.TelegramUserID] += giverPoints
+	}
+
+	effects := []Effect{
+		e.stop(TimerGuess),
+		e.stop(TimerGuessWarning),
+		IncrementStat{PlayerID: ev.Player.TelegramUserID, Field: "words_guessed_count", Value: 1},
+		IncrementStat{PlayerID: s.ClueGiver.TelegramUserID, Field: "clue_success_count", Value: 1},
+		RecordGuessTime{PlayerID: ev.Player.TelegramUserID, Seconds: timeTaken},
+		RoundWon{
+			Winner:          ev.Player,
+			ClueGiver:       s.ClueGiver,
+			Word:            s.Word.Word,
+			Points:          points,
+			TimeTaken:       timeTaken,
+			ClueGiverPoints: giverPoints,
+		},
+	}
+	return append(effects, e.endRound()...), nil
+}
+
+// skipTurn melewati giliran Pemberi Petunjuk tanpa membuka kata rahasia.
+// Pemain yang terlewat MaxMissedTurns kali berturut-turut dikeluarkan dari TurnOrder.
+func (e *Engine) skipTurn(reason SkipReason) []Effect {
+	s := e.State
+	giver := s.ClueGiver
+	s.MissedTurns[giver.TelegramUserID]++
+	missed := s.MissedTurns[giver.TelegramUserID]
+
+	effects := []Effect{
+		e.stop(TimerClueReminder),
+		e.stop(TimerClueDeadline),
+		IncrementStat{PlayerID: giver.TelegramUserID, Field: "missed_turns_count", Value: 1},
+		TurnSkipped{ClueGiver: giver, Reason: reason},
+	}
+	if s.Settings.MaxMissedTurns > 0 && missed >= s.Settings.MaxMissedTurns {
+		if s.removeFromTurnOrder(giver.TelegramUserID) {
+			effects = append(effects, PlayerBenched{Player: giver, MissedTurns: missed})
+		}
+	}
+	e.recordRound(db.RoundOutcomeSkipped, nil, 0)
+	return append(effects, e.endRound()...)
+}
+
+func (e *Engine) timeout(ev TimeoutEvent) []Effect {
+	s := e.State
+	// Timer dari ronde sebelumnya diabaikan.
+	if ev.Round != s.Round {
+		return nil
+	}
+	delete(s.Deadlines, ev.Timer)
+
+	switch ev.Timer {
+	case TimerAutoStart:
+		if s.Status != StatusLobby || len(s.Players) < MinPlayers {
+			return nil
+		}
+		return e.begin(true)
+	case TimerLobbyExpiry:
+		if s.Status != StatusLobby {
+			return nil
+		}
+		return e.finish(EndReasonExpired)
+	case TimerNextRound:
+		if s.Status != StatusIntermission {
+			return nil
+		}
+		if s.Round >= s.TotalRounds {
+			return e.finish(EndReasonCompleted)
+		}
+		return e.startRound()
+	case TimerClueReminder:
+		if s.Status != StatusWaitingForClue {
+			return nil
+		}
+		return []Effect{ClueReminder{ClueGiver: s.ClueGiver}}
+	case TimerClueDeadline:
+		if s.Status != StatusWaitingForClue {
+			return nil
+		}
+		return e.skipTurn(SkipReasonTimeout)
+	case TimerGuessWarning:
+		if s.Status != StatusWaitingForGuesses {
+			return nil
+		}
+		return []Effect{GuessWarning{SecondsLeft: int(s.Settings.WarningBefore.Seconds())}}
+	case TimerGuess:
+		if s.Status != StatusWaitingForGuesses {
+			return nil
+		}
+		effects := []Effect{
+			e.stop(TimerGuessWarning),
+			TimesUp{Word: s.Word.Word},
+		}
+		e.recordRound(db.RoundOutcomeTimeout, nil, 0)
+		return append(effects, e.endRound()...)
+	}
+	return nil
+}
+
+// arm mencatat deadline timer di State lalu meminta timer dipasang.
+func (e *Engine) arm(kind TimerKind, after time.Duration) Effect {
+	s := e.State
+	if s.Deadlines == nil {
+		s.Deadlines = make(map[TimerKind]Deadline)
+	}
+	s.Deadlines[kind] = Deadline{Round: s.Round, At: e.Now().Add(after)}
+	return ArmTimer{Timer: kind, Round: s.Round, After: after}
+}
+
+func (e *Engine) stop(kind TimerKind) Effect {
+	delete(e.State.Deadlines, kind)
+	return StopTimer{Timer: kind}
+}
+
+// PendingTimers menghitung ulang timer yang masih tersimpan di State,
+// dipakai saat memulihkan permainan setelah restart. Deadline yang sudah
+// lewat langsung dipasang dengan durasi nol.
+func (e *Engine) PendingTimers() []ArmTimer {
+	now := e.Now()
+	timers := make([]ArmTimer, 0, len(e.State.Deadlines))
+	for kind, d := range e.State.Deadlines {
+		after := d.At.Sub(now)
+		if after < 0 {
+			after = 0
+		}
+		timers = append(timers, ArmTimer{Timer: kind, Round: d.Round, After: after})
+	}
+	return timers
+}
+
+func (e *Engine) endRound() []Effect {
+	s := e.State
+	s.Status = StatusIntermission
+	return []Effect{
+		RoundScoreboard{Standings: s.Standings()},
+		e.arm(TimerNextRound, RoundBreak),
+	}
+}
+
+func (e *Engine) finish(reason EndReason) []Effect {
+	s := e.State
+	effects := make([]Effect, 0, len(allTimers))
+	for _, kind := range allTimers {
+		effects = append(effects, e.stop(kind))
+	}
+
+	standings := s.Standings()
+	started := s.Status != StatusLobby
+	// Ronde yang sedang berjalan saat permainan dihentikan tetap dicatat.
+	if s.Status == StatusWaitingForClue || s.Status == StatusWaitingForGuesses {
+		e.recordRound(db.RoundOutcomeUnfinished, nil, 0)
+	}
+	// Lobi yang ditutup sebelum dimulai tidak dihitung sebagai permainan.
+	if !started {
+		effects = append(effects, LobbyClosed{LobbyMessageID: s.LobbyMessageID, Reason: reason})
+	}
+
+	winners := Winners(standings)
+	winCounted := started && reason != EndReasonAbandoned && len(standings) >= s.Settings.MinPlayersForWin
+	if started {
+		for _, st := range standings {
+			effects = append(effects, IncrementStat{PlayerID: st.Player.TelegramUserID, Field: "games_played", Value: 1})
+		}
+		if winCounted {
+			won := make(map[int64]bool, len(winners))
+			for _, w := range winners {
+				won[w.TelegramUserID] = true
+			}
+			for _, st := range standings {
+				id := st.Player.TelegramUserID
+				effects = append(effects, GameResult{PlayerID: id, Won: won[id], Tied: won[id] && len(winners) > 1})
+			}
+		}
+		// Rating hanya berubah jika ada lawan dan ada yang mencetak poin.
+		if reason != EndReasonAbandoned && len(standings) >= 2 && len(winners) > 0 {
+			effects = append(effects, RateGame{Standings: standings})
+		}
+		for _, st := range standings {
+			if st.Points > 0 {
+				effects = append(effects, AwardPoints{PlayerID: st.Player.TelegramUserID, Points: st.Points})
+			}
+		}
+	}
+
+	var record *db.GameDetail
+	if started {
+		record = e.gameRecord(reason, standings, winners, winCounted)
+	}
+
+	s.Status = StatusFinished
+	s.IsActive = false
+
+	return append(effects, GameOver{
+		Reason:     reason,
+		Rounds:     s.Round,
+		Standings:  standings,
+		Winners:    winners,
+		WinCounted: winCounted,
+		Started:    started,
+		Record:     record,
+	})
+}
+
+// recordGuess mencatat satu tebakan di ronde yang sedang berjalan.
+func (e *Engine) recordGuess(ev GuessEvent, result string, seconds float64) {
+	s := e.State
+	s.Guesses = append(s.Guesses, db.GuessRecord{
+		GameID:   s.GameID,
+		Round:    s.Round,
+		PlayerID: ev.Player.TelegramUserID,
+		Text:     ev.Text,
+		Result:   result,
+		Seconds:  seconds,
+	})
+}
+
+// recordRound mencatat hasil ronde yang baru selesai. winner nil berarti
+// tidak ada yang menebak dengan benar.
+func (e *Engine) recordRound(outcome string, winner *db.Player, seconds float64) {
+	s := e.State
+	round := db.RoundRecord{
+		GameID:      s.GameID,
+		Round:       s.Round,
+		ClueGiverID: s.ClueGiver.TelegramUserID,
+		Word:        s.Word.Word,
+		Clue:        s.Clue,
+		Outcome:     outcome,
+	}
+	if winner != nil {
+		id := winner.TelegramUserID
+		round.WinnerID = &id
+		round.GuessSeconds = &seconds
+	}
+	s.History = append(s.History, round)
+}
+
+// gameRecord menyusun riwayat lengkap permainan yang baru selesai.
+func (e *Engine) gameRecord(reason EndReason, standings []Standing, winners []*db.Player, winCounted bool) *db.GameDetail {
+	s := e.State
+	game := db.GameRecord{
+		ID:           s.GameID,
+		ChatID:       s.ChatID,
+		Mode:         s.Mode,
+		Category:     s.Category,
+		RoundsPlayed: s.Round,
+		TotalRounds:  s.TotalRounds,
+		EndReason:    string(reason),
+		WinCounted:   winCounted,
+		StartedAt:    s.StartedAt,
+		EndedAt:      e.Now(),
+	}
+	// winner_id hanya diisi jika pemenangnya tunggal; pemenang yang seri ada di game_players.
+	if len(winners) == 1 {
+		id := winners[0].TelegramUserID
+		game.WinnerID = &id
+	}
+
+	players := make([]db.GamePlayerRecord, 0, len(standings))
+	for _, st := range standings {
+		_, left := s.LeftPlayers[st.Player.TelegramUserID]
+		players = append(players, db.GamePlayerRecord{
+			GameID:    s.GameID,
+			PlayerID:  st.Player.TelegramUserID,
+			FirstName: st.Player.FirstName,
+			Points:    st.Points,
+			LeftEarly: left,
+			Placement: st.Placement,
+			Won:       winCounted && st.Placement == 1 && st.Points > 0,
+		})
+	}
+
+	return &db.GameDetail{
+		Game:    game,
+		Players: players,
+		Rounds:  append([]db.RoundRecord(nil), s.History...),
+		Guesses: append([]db.GuessRecord(nil), s.Guesses...),
+	}
+}
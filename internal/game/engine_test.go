@@ -0,0 +1,392 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+var (
+	host  = &db.Player{TelegramUserID: 1, FirstName: "Ani"}
+	guest = &db.Player{TelegramUserID: 2, FirstName: "Budi"}
+	third = &db.Player{TelegramUserID: 3, FirstName: "Citra"}
+	late  = &db.Player{TelegramUserID: 4, FirstName: "Dodi"}
+)
+
+// testEngine membuat Engine dengan jam, pengacak dan pemilih kata yang bisa
+// diatur, jadi hasil setiap langkah selalu sama.
+func testEngine(rounds int) (*Engine, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := NewEngine(NewGame(-100, host, rounds, DefaultSettings()))
+	e.Now = func() time.Time { return now }
+	e.Shuffle = func(int, func(i, j int)) {}
+	e.PickWord = func(*GameState) db.Word { return db.Word{Word: "kucing"} }
+	return e, &now
+}
+
+// step adalah satu event beserta hasil yang diharapkan.
+type step struct {
+	name       string
+	advance    time.Duration
+	event      Event
+	wantErr    error
+	wantStatus string
+	// wantClueGiver, jika diisi, adalah ID Pemberi Petunjuk setelah event.
+	wantClueGiver int64
+	// wantEffects berisi tipe efek (misalnya "game.RoundWon") yang harus muncul.
+	wantEffects []string
+}
+
+func runSteps(t *testing.T, e *Engine, now *time.Time, steps []step) {
+	t.Helper()
+	for _, st := range steps {
+		*now = now.Add(st.advance)
+		effects, err := e.Handle(st.event)
+		if !errors.Is(err, st.wantErr) {
+			t.Fatalf("%s: error = %v, want %v", st.name, err, st.wantErr)
+		}
+		if st.wantStatus != "" && e.State.Status != st.wantStatus {
+			t.Fatalf("%s: status = %s, want %s", st.name, e.State.Status, st.wantStatus)
+		}
+		if st.wantClueGiver != 0 && e.State.ClueGiver.TelegramUserID != st.wantClueGiver {
+			t.Fatalf("%s: clue giver = %d, want %d", st.name, e.State.ClueGiver.TelegramUserID, st.wantClueGiver)
+		}
+		for _, want := range st.wantEffects {
+			if !hasEffect(effects, want) {
+				t.Fatalf("%s: missing effect %s in %v", st.name, want, effectTypes(effects))
+			}
+		}
+	}
+}
+
+func hasEffect(effects []Effect, name string) bool {
+	for _, eff := range effects {
+		if fmt.Sprintf("%T", eff) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func effectTypes(effects []Effect) []string {
+	names := make([]string, len(effects))
+	for i, eff := range effects {
+		names[i] = fmt.Sprintf("%T", eff)
+	}
+	return names
+}
+
+// lobbySteps membuka lobi, memasukkan dua pemain dan memulai ronde pertama.
+// Ani memberi petunjuk lebih dulu karena pengacaknya tidak mengubah urutan.
+func lobbySteps() []step {
+	return []step{
+		{name: "open lobby", event: OpenLobbyEvent{}, wantStatus: StatusLobby, wantEffects: []string{"game.ArmTimer"}},
+		{name: "host joins", event: JoinEvent{Player: host}, wantEffects: []string{"game.LobbyChanged"}},
+		{name: "start alone", event: StartEvent{PlayerID: host.TelegramUserID}, wantErr: ErrNotEnoughPlayers},
+		{name: "guest joins", event: JoinEvent{Player: guest}, wantEffects: []string{"game.LobbyChanged"}},
+		{name: "guest joins twice", event: JoinEvent{Player: guest}, wantErr: ErrAlreadyJoined},
+		{name: "guest starts", event: StartEvent{PlayerID: guest.TelegramUserID}, wantErr: ErrNotHost},
+		{name: "host starts", event: StartEvent{PlayerID: host.TelegramUserID}, wantStatus: StatusIntermission, wantEffects: []string{"game.GameStarted", "game.ArmTimer"}},
+		{name: "first round", advance: StartDelay, event: TimeoutEvent{Timer: TimerNextRound, Round: 0}, wantStatus: StatusWaitingForClue, wantEffects: []string{"game.RoundStarted"}},
+	}
+}
+
+func TestEngineScenarios(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+		check func(t *testing.T, e *Engine)
+	}{
+		{
+			name: "clue and correct guess",
+			steps: []step{
+				{name: "guess before clue", event: GuessEvent{Player: guest, Text: "kucing"}, wantErr: ErrUnexpectedEvent},
+				{name: "wrong clue giver", event: ClueEvent{PlayerID: guest.TelegramUserID, Text: "hewan"}, wantErr: ErrNotClueGiver},
+				{name: "two-word clue", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "hewan lucu"}, wantErr: ErrClueNotOneWord},
+				{name: "clue is the word", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "Kucing"}, wantErr: ErrClueIsSecretWord},
+				{name: "clue", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "meong"}, wantStatus: StatusWaitingForGuesses, wantEffects: []string{"game.ClueAccepted", "game.ArmTimer"}},
+				{name: "clue giver guesses", event: GuessEvent{Player: host, Text: "kucing"}, wantErr: ErrClueGiverGuess},
+				{name: "outsider guesses", event: GuessEvent{Player: third, Text: "kucing"}, wantErr: ErrNotParticipant},
+				{name: "wrong guess", advance: 5 * time.Second, event: GuessEvent{Player: guest, Text: "anjing"}, wantStatus: StatusWaitingForGuesses, wantEffects: []string{"game.WrongGuess"}},
+				{name: "correct guess", advance: 5 * time.Second, event: GuessEvent{Player: guest, Text: "KUCING"}, wantStatus: StatusIntermission, wantEffects: []string{"game.RoundWon", "game.RoundScoreboard"}},
+			},
+			check: func(t *testing.T, e *Engine) {
+				// Ditebak dalam 10 detik dari 60: tingkatan poin pertama.
+				if got := e.State.SessionScores[guest.TelegramUserID]; got != 20 {
+					t.Errorf("guest score = %d, want 20", got)
+				}
+				if len(e.State.History) != 1 || e.State.History[0].Outcome != db.RoundOutcomeWon {
+					t.Errorf("history = %+v, want one won round", e.State.History)
+				}
+			},
+		},
+		{
+			name: "guess timer runs out",
+			steps: []step{
+				{name: "clue", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "meong"}, wantStatus: StatusWaitingForGuesses},
+				{name: "warning", advance: 45 * time.Second, event: TimeoutEvent{Timer: TimerGuessWarning, Round: 1}, wantEffects: []string{"game.GuessWarning"}},
+				{name: "stale timer", event: TimeoutEvent{Timer: TimerGuess, Round: 0}, wantStatus: StatusWaitingForGuesses},
+				{name: "time up", advance: 15 * time.Second, event: TimeoutEvent{Timer: TimerGuess, Round: 1}, wantStatus: StatusIntermission, wantEffects: []string{"game.TimesUp", "game.RoundScoreboard"}},
+				{name: "late guess", event: GuessEvent{Player: guest, Text: "kucing"}, wantErr: ErrUnexpectedEvent},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if got := e.State.SessionScores[guest.TelegramUserID]; got != 0 {
+					t.Errorf("guest score = %d, want 0", got)
+				}
+			},
+		},
+		{
+			name: "clue deadline skips the turn",
+			steps: []step{
+				{name: "reminder", advance: 90 * time.Second, event: TimeoutEvent{Timer: TimerClueReminder, Round: 1}, wantEffects: []string{"game.ClueReminder"}},
+				{name: "deadline", advance: 90 * time.Second, event: TimeoutEvent{Timer: TimerClueDeadline, Round: 1}, wantStatus: StatusIntermission, wantEffects: []string{"game.TurnSkipped"}},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if got := e.State.MissedTurns[host.TelegramUserID]; got != 1 {
+					t.Errorf("missed turns = %d, want 1", got)
+				}
+			},
+		},
+		{
+			name: "game finishes after the last round",
+			steps: []step{
+				{name: "clue", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "meong"}},
+				{name: "correct guess", advance: time.Second, event: GuessEvent{Player: guest, Text: "kucing"}, wantStatus: StatusIntermission},
+				{name: "finish", advance: RoundBreak, event: TimeoutEvent{Timer: TimerNextRound, Round: 1}, wantStatus: StatusFinished, wantEffects: []string{"game.GameOver", "game.AwardPoints"}},
+				{name: "after finish", event: GuessEvent{Player: guest, Text: "kucing"}, wantErr: ErrGameNotActive},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if len(e.State.Deadlines) != 0 {
+					t.Errorf("deadlines left after finish: %v", e.State.Deadlines)
+				}
+			},
+		},
+		{
+			name: "host ends the game",
+			steps: []step{
+				{name: "guest ends", event: EndEvent{PlayerID: guest.TelegramUserID}, wantErr: ErrNotHost},
+				{name: "host ends", event: EndEvent{PlayerID: host.TelegramUserID}, wantStatus: StatusFinished, wantEffects: []string{"game.GameOver"}},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if len(e.State.History) != 1 || e.State.History[0].Outcome != db.RoundOutcomeUnfinished {
+					t.Errorf("history = %+v, want one unfinished round", e.State.History)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, now := testEngine(1)
+			runSteps(t, e, now, append(lobbySteps(), tt.steps...))
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+}
+
+// threePlayerSteps seperti lobbySteps, tetapi Citra ikut sebelum permainan
+// dimulai, jadi urutan gilirannya Ani, Budi, Citra.
+func threePlayerSteps() []step {
+	steps := lobbySteps()
+	return append(append(steps[:4:4],
+		step{name: "third joins", event: JoinEvent{Player: third}, wantEffects: []string{"game.LobbyChanged"}}),
+		steps[6:]...)
+}
+
+// skipTo melewati ronde round karena batas waktu petunjuk lalu memulai ronde
+// berikutnya dan memeriksa Pemberi Petunjuknya.
+func skipTo(round int, giver *db.Player) []step {
+	return []step{
+		{name: fmt.Sprintf("round %d deadline", round), event: TimeoutEvent{Timer: TimerClueDeadline, Round: round}, wantStatus: StatusIntermission},
+		{name: fmt.Sprintf("round %d", round+1), advance: RoundBreak, event: TimeoutEvent{Timer: TimerNextRound, Round: round}, wantStatus: StatusWaitingForClue, wantClueGiver: giver.TelegramUserID},
+	}
+}
+
+func TestEngineTurnOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+		check func(t *testing.T, e *Engine)
+	}{
+		{
+			name: "late join goes right after the clue giver",
+			steps: concat(
+				[]step{
+					{name: "clue", event: ClueEvent{PlayerID: host.TelegramUserID, Text: "meong"}, wantStatus: StatusWaitingForGuesses},
+					{name: "late join", event: JoinEvent{Player: late}, wantStatus: StatusWaitingForGuesses, wantClueGiver: host.TelegramUserID, wantEffects: []string{"game.PlayerJoined"}},
+					{name: "time up", advance: 60 * time.Second, event: TimeoutEvent{Timer: TimerGuess, Round: 1}, wantStatus: StatusIntermission},
+					{name: "round 2", advance: RoundBreak, event: TimeoutEvent{Timer: TimerNextRound, Round: 1}, wantClueGiver: late.TelegramUserID},
+				},
+				skipTo(2, guest),
+				skipTo(3, third),
+				skipTo(4, host),
+			),
+		},
+		{
+			name: "clue giver leaves",
+			steps: concat(
+				[]step{
+					{name: "clue giver leaves", event: LeaveEvent{PlayerID: host.TelegramUserID}, wantStatus: StatusIntermission, wantEffects: []string{"game.PlayerLeft", "game.TurnSkipped"}},
+					{name: "round 2", advance: RoundBreak, event: TimeoutEvent{Timer: TimerNextRound, Round: 1}, wantClueGiver: guest.TelegramUserID},
+				},
+				skipTo(2, third),
+				skipTo(3, guest),
+			),
+		},
+		{
+			name: "earlier player leaves",
+			steps: concat(
+				skipTo(1, guest),
+				skipTo(2, third),
+				[]step{
+					{name: "guest leaves", event: LeaveEvent{PlayerID: guest.TelegramUserID}, wantStatus: StatusWaitingForClue, wantClueGiver: third.TelegramUserID, wantEffects: []string{"game.PlayerLeft"}},
+				},
+				skipTo(3, host),
+			),
+			check: func(t *testing.T, e *Engine) {
+				order := e.State.TurnOrder
+				if len(order) != 2 || order[0] != host || order[1] != third || e.State.CurrentTurnIndex != 0 {
+					t.Errorf("turn order = %v at %d, want [Ani Citra] at 0", order, e.State.CurrentTurnIndex)
+				}
+			},
+		},
+		{
+			name: "host leaves",
+			steps: concat(
+				skipTo(1, guest),
+				[]step{
+					{name: "host leaves", event: LeaveEvent{PlayerID: host.TelegramUserID}, wantStatus: StatusWaitingForClue, wantClueGiver: guest.TelegramUserID, wantEffects: []string{"game.PlayerLeft", "game.HostChanged"}},
+				},
+			),
+			check: func(t *testing.T, e *Engine) {
+				if e.State.Host.TelegramUserID != third.TelegramUserID {
+					t.Errorf("host = %d, want %d", e.State.Host.TelegramUserID, third.TelegramUserID)
+				}
+			},
+		},
+		{
+			name: "too few players left",
+			steps: []step{
+				{name: "third leaves", event: LeaveEvent{PlayerID: third.TelegramUserID}, wantStatus: StatusWaitingForClue, wantEffects: []string{"game.PlayerLeft"}},
+				{name: "guest leaves", event: LeaveEvent{PlayerID: guest.TelegramUserID}, wantStatus: StatusFinished, wantEffects: []string{"game.PlayerLeft", "game.GameOver"}},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if len(e.State.Deadlines) != 0 {
+					t.Errorf("deadlines left after finish: %v", e.State.Deadlines)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, now := testEngine(10)
+			runSteps(t, e, now, append(threePlayerSteps(), tt.steps...))
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+}
+
+func concat(parts ...[]step) []step {
+	var steps []step
+	for _, p := range parts {
+		steps = append(steps, p...)
+	}
+	return steps
+}
+
+func TestEngineAutoStart(t *testing.T) {
+	lobby := []step{
+		{name: "open lobby", event: OpenLobbyEvent{}, wantStatus: StatusLobby},
+		{name: "host joins", event: JoinEvent{Player: host}},
+		{name: "guest joins", event: JoinEvent{Player: guest}, wantEffects: []string{"game.ArmTimer"}},
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		check func(t *testing.T, e *Engine)
+	}{
+		{
+			name: "countdown starts the game",
+			steps: []step{
+				{name: "countdown ends", advance: 30 * time.Second, event: TimeoutEvent{Timer: TimerAutoStart, Round: 0}, wantStatus: StatusIntermission, wantEffects: []string{"game.GameStarted"}},
+			},
+			check: func(t *testing.T, e *Engine) {
+				if len(e.State.TurnOrder) != 2 {
+					t.Errorf("turn order has %d players, want 2", len(e.State.TurnOrder))
+				}
+			},
+		},
+		{
+			name: "countdown ends with too few players",
+			steps: []step{
+				{name: "guest leaves", event: LeaveEvent{PlayerID: guest.TelegramUserID}, wantStatus: StatusLobby, wantEffects: []string{"game.StopTimer"}},
+				{name: "stale countdown", advance: 30 * time.Second, event: TimeoutEvent{Timer: TimerAutoStart, Round: 0}, wantStatus: StatusLobby},
+				{name: "lobby expires", advance: 10 * time.Minute, event: TimeoutEvent{Timer: TimerLobbyExpiry, Round: 0}, wantStatus: StatusFinished, wantEffects: []string{"game.LobbyClosed"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, now := testEngine(3)
+			e.State.Settings.AutoStart = 30 * time.Second
+			runSteps(t, e, now, append(lobby, tt.steps...))
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+
+	t.Run("manual start stops the countdown", func(t *testing.T) {
+		e, now := testEngine(3)
+		e.State.Settings.AutoStart = 30 * time.Second
+		runSteps(t, e, now, lobby)
+		effects, err := e.Handle(StartEvent{PlayerID: host.TelegramUserID})
+		if err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		stopped := false
+		for _, eff := range effects {
+			if st, ok := eff.(StopTimer); ok && st.Timer == TimerAutoStart {
+				stopped = true
+			}
+			if gs, ok := eff.(GameStarted); ok && gs.AutoStarted {
+				t.Error("manual start reported as auto-started")
+			}
+		}
+		if !stopped {
+			t.Errorf("effects %v do not stop the auto-start timer", effectTypes(effects))
+		}
+		if _, ok := e.AutoStartIn(); ok {
+			t.Error("auto-start countdown still pending after manual start")
+		}
+	})
+}
+
+func TestEngineLobbyExpires(t *testing.T) {
+	e, now := testEngine(3)
+	runSteps(t, e, now, []step{
+		{name: "open lobby", event: OpenLobbyEvent{}},
+		{name: "host joins", event: JoinEvent{Player: host}},
+		{name: "expire", advance: 10 * time.Minute, event: TimeoutEvent{Timer: TimerLobbyExpiry, Round: 0}, wantStatus: StatusFinished, wantEffects: []string{"game.LobbyClosed", "game.GameOver"}},
+	})
+}
+
+func TestEngineTimersUseInjectedClock(t *testing.T) {
+	e, now := testEngine(3)
+	runSteps(t, e, now, lobbySteps())
+	*now = now.Add(30 * time.Second)
+	for _, timer := range e.PendingTimers() {
+		if timer.Timer == TimerClueReminder && timer.After != 60*time.Second {
+			t.Errorf("clue reminder after %v, want 60s", timer.After)
+		}
+	}
+}
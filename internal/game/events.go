@@ -0,0 +1,323 @@
+package game
+
+import (
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// TimerKind menamai setiap timer yang bisa diminta oleh engine.
+type TimerKind string
+
+const (
+	TimerNextRound    TimerKind = "next_round"
+	TimerClueReminder TimerKind = "clue_reminder"
+	TimerClueDeadline TimerKind = "clue_deadline"
+	TimerGuess        TimerKind = "guess"
+	TimerGuessWarning TimerKind = "guess_warning"
+	TimerAutoStart    TimerKind = "auto_start"
+	TimerLobbyExpiry  TimerKind = "lobby_expiry"
+)
+
+// allTimers dipakai untuk menghentikan semua timer saat permainan selesai.
+var allTimers = []TimerKind{TimerNextRound, TimerClueReminder, TimerClueDeadline, TimerGuess, TimerGuessWarning, TimerAutoStart, TimerLobbyExpiry}
+
+// SkipReason menjelaskan kenapa giliran Pemberi Petunjuk dilewati.
+type SkipReason string
+
+const (
+	SkipReasonPMFailed SkipReason = "pm_failed"
+	SkipReasonTimeout  SkipReason = "timeout"
+	SkipReasonLeft     SkipReason = "left"
+)
+
+// EndReason menjelaskan kenapa sebuah permainan berakhir.
+type EndReason string
+
+const (
+	EndReasonCompleted EndReason = "completed"
+	EndReasonHost      EndReason = "host"
+	// EndReasonAbandoned berarti pemain yang tersisa terlalu sedikit untuk melanjutkan.
+	EndReasonAbandoned EndReason = "abandoned"
+	// EndReasonExpired berarti lobi ditutup karena tidak kunjung dimulai.
+	EndReasonExpired EndReason = "expired"
+)
+
+// Event adalah masukan untuk Engine: aksi pemain atau timer yang habis.
+type Event interface {
+	isEvent()
+}
+
+// OpenLobbyEvent dikirim sekali setelah lobi dibuat, untuk memasang batas waktu lobi.
+type OpenLobbyEvent struct{}
+
+// JoinEvent dikirim saat pemain menekan tombol ikut main.
+type JoinEvent struct {
+	Player *db.Player
+}
+
+// LeaveEvent dikirim saat pemain keluar dari lobi atau permainan yang sedang berjalan.
+type LeaveEvent struct {
+	PlayerID int64
+}
+
+// StartEvent dikirim saat host mengetik /play.
+type StartEvent struct {
+	PlayerID int64
+}
+
+// ClueEvent membawa petunjuk yang dikirim lewat PM.
+type ClueEvent struct {
+	PlayerID int64
+	Text     string
+}
+
+// GuessEvent membawa tebakan yang dibalas ke pesan petunjuk.
+type GuessEvent struct {
+	Player *db.Player
+	Text   string
+}
+
+// RerollEvent dikirim saat Pemberi Petunjuk meminta kata rahasia diganti.
+type RerollEvent struct {
+	PlayerID int64
+}
+
+// SkipTurnEvent melewati giliran Pemberi Petunjuk, misalnya saat PM gagal terkirim.
+type SkipTurnEvent struct {
+	Reason SkipReason
+}
+
+// TimeoutEvent dikirim ketika timer yang diminta lewat ArmTimer habis.
+type TimeoutEvent struct {
+	Timer TimerKind
+	Round int
+}
+
+// EndEvent mengakhiri permainan. PlayerID 0 berarti dihentikan oleh sistem.
+type EndEvent struct {
+	PlayerID int64
+}
+
+func (OpenLobbyEvent) isEvent() {}
+func (JoinEvent) isEvent()      {}
+func (LeaveEvent) isEvent()     {}
+func (StartEvent) isEvent()     {}
+func (ClueEvent) isEvent()      {}
+func (GuessEvent) isEvent()     {}
+func (RerollEvent) isEvent()    {}
+func (SkipTurnEvent) isEvent()  {}
+func (TimeoutEvent) isEvent()   {}
+func (EndEvent) isEvent()       {}
+
+// Effect adalah keluaran Engine yang harus dijalankan oleh lapisan bot.
+type Effect interface {
+	isEffect()
+}
+
+// LobbyChanged meminta pesan lobi diperbarui.
+type LobbyChanged struct{}
+
+// PlayerJoined diumumkan saat pemain bergabung ke permainan yang sudah berjalan.
+type PlayerJoined struct {
+	Player *db.Player
+}
+
+// PlayerLeft diumumkan saat pemain keluar dari permainan yang sudah berjalan.
+type PlayerLeft struct {
+	Player *db.Player
+	Points int
+}
+
+// HostChanged diumumkan saat host keluar dan digantikan pemain lain.
+type HostChanged struct {
+	Host *db.Player
+}
+
+// GameStarted menandai lobi ditutup dan permainan dimulai.
+type GameStarted struct {
+	LobbyMessageID int
+	AutoStarted    bool
+}
+
+// LobbyClosed menandai lobi ditutup sebelum permainan dimulai, karena kedaluwarsa
+// atau karena semua pemain keluar.
+type LobbyClosed struct {
+	LobbyMessageID int
+	Reason         EndReason
+}
+
+// ArmTimer meminta sebuah timer dipasang. Saat habis, kirim TimeoutEvent dengan Round yang sama.
+type ArmTimer struct {
+	Timer TimerKind
+	Round int
+	After time.Duration
+}
+
+// StopTimer meminta sebuah timer dihentikan.
+type StopTimer struct {
+	Timer TimerKind
+}
+
+// RoundStarted mengumumkan ronde baru dan kata rahasia untuk Pemberi Petunjuk.
+type RoundStarted struct {
+	Round       int
+	TotalRounds int
+	ClueGiver   *db.Player
+	SecretWord  string
+	// Taboo berisi kata terlarang untuk petunjuk, hanya terisi pada mode tabu.
+	Taboo       []string
+	RerollsLeft int
+}
+
+// WordRerolled mengirim kata rahasia pengganti ke Pemberi Petunjuk.
+type WordRerolled struct {
+	Round       int
+	ClueGiver   *db.Player
+	OldWord     string
+	NewWord     string
+	Taboo       []string
+	RerollsLeft int
+}
+
+// ClueReminder mengingatkan Pemberi Petunjuk yang belum mengirim petunjuk.
+type ClueReminder struct {
+	ClueGiver *db.Player
+}
+
+// ClueAccepted mengumumkan petunjuk di grup.
+type ClueAccepted struct {
+	Round        int
+	ClueGiver    *db.Player
+	Clue         string
+	GuessSeconds int
+}
+
+// WrongGuess meminta daftar tebakan salah di pesan petunjuk diperbarui.
+type WrongGuess struct {
+	Round        int
+	ClueGiver    *db.Player
+	Clue         string
+	GuessSeconds int
+	WrongGuesses []string
+}
+
+// CloseGuess memberi tahu penebak secara pribadi bahwa tebakannya hampir benar.
+type CloseGuess struct {
+	Player *db.Player
+}
+
+// RoundWon diumumkan saat ada tebakan yang benar.
+type RoundWon struct {
+	Winner    *db.Player
+	ClueGiver *db.Player
+	Word      string
+	Points    int
+	TimeTaken float64
+	// ClueGiverPoints adalah poin untuk Pemberi Petunjuk, hanya diberikan pada mode tabu.
+	ClueGiverPoints int
+}
+
+// GuessWarning memberi tahu grup bahwa waktu menebak hampir habis.
+type GuessWarning struct {
+	SecondsLeft int
+}
+
+// TimesUp diumumkan saat tidak ada yang menebak dengan benar.
+type TimesUp struct {
+	Word string
+}
+
+// TurnSkipped diumumkan saat giliran Pemberi Petunjuk dilewati.
+type TurnSkipped struct {
+	ClueGiver *db.Player
+	Reason    SkipReason
+}
+
+// PlayerBenched diumumkan saat pemain dikeluarkan dari giliran karena terlalu sering terlewat.
+type PlayerBenched struct {
+	Player      *db.Player
+	MissedTurns int
+}
+
+// RoundScoreboard menampilkan skor sementara di akhir ronde.
+type RoundScoreboard struct {
+	Standings []Standing
+}
+
+// GameOver menampilkan hasil akhir. Setelah efek ini, permainan tidak lagi aktif.
+type GameOver struct {
+	Reason    EndReason
+	Rounds    int
+	Standings []Standing
+	// Winners berisi lebih dari satu pemain jika skor tertinggi seri.
+	Winners []*db.Player
+	// WinCounted bernilai false jika pemainnya terlalu sedikit atau permainan
+	// ditinggalkan, sehingga kemenangan tidak masuk statistik.
+	WinCounted bool
+	// Started bernilai false jika permainan berakhir saat masih di lobi.
+	Started bool
+	// Record berisi riwayat lengkap permainan untuk disimpan, nil jika belum dimulai.
+	Record *db.GameDetail
+}
+
+// IncrementStat menambah kolom statistik pemain di database.
+type IncrementStat struct {
+	PlayerID int64
+	Field    string
+	Value    int
+}
+
+// RecordGuessTime mencatat waktu tebakan untuk rekor tebakan tercepat.
+type RecordGuessTime struct {
+	PlayerID int64
+	Seconds  float64
+}
+
+// GameResult mencatat hasil permainan yang kemenangannya dihitung untuk satu
+// pemain: menang atau tidak, untuk statistik games_won dan rentetan menang.
+// Tied bernilai true jika kemenangannya dibagi dengan pemain lain.
+type GameResult struct {
+	PlayerID int64
+	Won      bool
+	Tied     bool
+}
+
+// RateGame memperbarui rating kemampuan semua pemain dari peringkat akhir
+// permainan.
+type RateGame struct {
+	Standings []Standing
+}
+
+// AwardPoints menambahkan poin sesi ke skor global pemain.
+type AwardPoints struct {
+	PlayerID int64
+	Points   int
+}
+
+func (LobbyChanged) isEffect()    {}
+func (PlayerJoined) isEffect()    {}
+func (PlayerLeft) isEffect()      {}
+func (HostChanged) isEffect()     {}
+func (GameStarted) isEffect()     {}
+func (LobbyClosed) isEffect()     {}
+func (ArmTimer) isEffect()        {}
+func (StopTimer) isEffect()       {}
+func (RoundStarted) isEffect()    {}
+func (WordRerolled) isEffect()    {}
+func (ClueReminder) isEffect()    {}
+func (ClueAccepted) isEffect()    {}
+func (WrongGuess) isEffect()      {}
+func (CloseGuess) isEffect()      {}
+func (RoundWon) isEffect()        {}
+func (GuessWarning) isEffect()    {}
+func (TimesUp) isEffect()         {}
+func (TurnSkipped) isEffect()     {}
+func (PlayerBenched) isEffect()   {}
+func (RoundScoreboard) isEffect() {}
+func (GameOver) isEffect()        {}
+func (IncrementStat) isEffect()   {}
+func (RecordGuessTime) isEffect() {}
+func (GameResult) isEffect()      {}
+func (RateGame) isEffect()        {}
+func (AwardPoints) isEffect()     {}
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"sort"
 	"time"
 
 	"detektif-kata-bot/internal/db"
@@ -10,29 +11,53 @@ const (
 	StatusLobby             = "lobby"
 	StatusWaitingForClue    = "waiting_for_clue"
 	StatusWaitingForGuesses = "waiting_for_guesses"
+	StatusIntermission      = "intermission"
+	StatusFinished          = "finished"
+)
+
+// Mode permainan grup yang dipilih saat /startgame.
+const (
+	ModeClassic = "classic"
+	ModeTaboo   = "taboo"
 )
 
 type GameState struct {
-	ChatID                   int64
-	Status                   string
-	Host                     *db.Player
-	Players                  map[int64]*db.Player
-	SessionScores            map[int64]int
-	TurnOrder                []*db.Player
-	CurrentTurnIndex         int
-	Round                    int
-	TotalRounds              int
-	LobbyMessageID           int
-	IsActive                 bool
-	SecretWord               string
-	Clue                     string
-	ClueMessageID            int
-	ClueGiver                *db.Player
-	Timer                    *time.Timer
-	ClueGiverReminderTimer   *time.Timer
-	GuessingTimeWarningTimer *time.Timer
-	WrongGuesses             []string // TANDA: Field yang hilang ditambahkan di sini
-	GuessingStartTime        time.Time
+	ChatID            int64
+	ChatTitle         string
+	Status            string
+	Host              *db.Player
+	Players           map[int64]*db.Player
+	LeftPlayers       map[int64]*db.Player // keluar di tengah permainan, skornya tetap dihitung
+	SessionScores     map[int64]int
+	TurnOrder         []*db.Player
+	CurrentTurnIndex  int
+	Round             int
+	TotalRounds       int
+	LobbyMessageID    int
+	IsActive          bool
+	Mode              string
+	Category          string
+	Word              db.Word
+	Clue              string
+	ClueMessageID     int
+	ClueGiver         *db.Player
+	WrongGuesses      []string
+	GuessingStartTime time.Time
+	Deadlines         map[TimerKind]Deadline
+	Settings          Settings
+	MissedTurns       map[int64]int
+	Rerolls           map[int64]int
+	GameID            string
+	StartedAt         time.Time
+	History           []db.RoundRecord // ronde yang sudah selesai, untuk tabel rounds
+	Guesses           []db.GuessRecord
+}
+
+// Deadline mencatat kapan sebuah timer engine akan habis, supaya timer bisa
+// dipasang ulang setelah bot restart.
+type Deadline struct {
+	Round int
+	At    time.Time
 }
 
 type SoloGameState struct {
@@ -42,18 +67,134 @@ type SoloGameState struct {
 	HintsGiven  int
 }
 
-func NewGame(chatID int64, host *db.Player, totalRounds int) *GameState {
+// Standing adalah posisi satu pemain di papan skor sesi. Pemain dengan skor
+// sama mendapat Placement yang sama (1, 1, 3, ...).
+type Standing struct {
+	Player    *db.Player
+	Points    int
+	Placement int
+}
+
+func NewGame(chatID int64, host *db.Player, totalRounds int, settings Settings) *GameState {
 	return &GameState{
 		ChatID:           chatID,
 		Status:           StatusLobby,
 		Host:             host,
 		Players:          make(map[int64]*db.Player),
+		LeftPlayers:      make(map[int64]*db.Player),
 		SessionScores:    make(map[int64]int),
 		TurnOrder:        make([]*db.Player, 0),
-		CurrentTurnIndex: 0,
+		CurrentTurnIndex: -1,
 		Round:            0,
-		TotalRounds:      totalRounds, // TANDA: Menggunakan nilai dari parameter
+		TotalRounds:      totalRounds,
 		IsActive:         true,
+		Mode:             ModeClassic,
 		WrongGuesses:     make([]string, 0),
+		Deadlines:        make(map[TimerKind]Deadline),
+		Settings:         settings,
+		MissedTurns:      make(map[int64]int),
+		Rerolls:          make(map[int64]int),
 	}
-}
\ No newline at end of file
+}
+
+// addToTurnOrder memasukkan pemain yang terlambat tepat setelah Pemberi
+// Petunjuk yang sedang berjalan, jadi ia mendapat giliran berikutnya tanpa
+// ada pemain lain yang terlewat atau mendapat giliran dua kali.
+func (s *GameState) addToTurnOrder(p *db.Player) {
+	i := s.CurrentTurnIndex + 1
+	s.TurnOrder = append(s.TurnOrder[:i], append([]*db.Player{p}, s.TurnOrder[i:]...)...)
+}
+
+// nextHost memilih pengganti host: pemain berikutnya dalam urutan giliran,
+// atau pemain dengan ID terkecil jika permainan belum dimulai.
+func (s *GameState) nextHost() *db.Player {
+	for i := 1; i <= len(s.TurnOrder); i++ {
+		p := s.TurnOrder[(s.CurrentTurnIndex+i+len(s.TurnOrder))%len(s.TurnOrder)]
+		if _, ok := s.Players[p.TelegramUserID]; ok {
+			return p
+		}
+	}
+	var next *db.Player
+	for _, p := range s.Players {
+		if next == nil || p.TelegramUserID < next.TelegramUserID {
+			next = p
+		}
+	}
+	return next
+}
+
+// removeFromTurnOrder mengeluarkan pemain dari urutan giliran tanpa
+// menggeser giliran pemain lain.
+func (s *GameState) removeFromTurnOrder(playerID int64) bool {
+	for i, p := range s.TurnOrder {
+		if p.TelegramUserID != playerID {
+			continue
+		}
+		s.TurnOrder = append(s.TurnOrder[:i], s.TurnOrder[i+1:]...)
+		if i <= s.CurrentTurnIndex {
+			s.CurrentTurnIndex--
+		}
+		return true
+	}
+	return false
+}
+
+func (s *GameState) guessSeconds() int {
+	return int(s.Settings.GuessDuration.Seconds())
+}
+
+// Standings mengurutkan semua pemain dari skor sesi tertinggi.
+// Skor yang sama diurutkan berdasarkan ID agar hasilnya selalu sama.
+func (s *GameState) Standings() []Standing {
+	standings := make([]Standing, 0, len(s.Players)+len(s.LeftPlayers))
+	for id, p := range s.Players {
+		standings = append(standings, Standing{Player: p, Points: s.SessionScores[id]})
+	}
+	for id, p := range s.LeftPlayers {
+		standings = append(standings, Standing{Player: p, Points: s.SessionScores[id]})
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		return standings[i].Player.TelegramUserID < standings[j].Player.TelegramUserID
+	})
+	for i := range standings {
+		if i > 0 && standings[i].Points == standings[i-1].Points {
+			standings[i].Placement = standings[i-1].Placement
+		} else {
+			standings[i].Placement = i + 1
+		}
+	}
+	return standings
+}
+
+// Winners mengembalikan semua pemain di peringkat pertama, termasuk yang seri.
+// Tidak ada pemenang jika tidak ada yang mendapat poin.
+func Winners(standings []Standing) []*db.Player {
+	var winners []*db.Player
+	for _, st := range standings {
+		if st.Placement != 1 || st.Points <= 0 {
+			break
+		}
+		winners = append(winners, st.Player)
+	}
+	return winners
+}
+
+// taboo mengembalikan daftar kata terlarang ronde ini, atau nil di luar mode tabu.
+func (s *GameState) taboo() []string {
+	if s.Mode != ModeTaboo {
+		return nil
+	}
+	return append([]string(nil), s.Word.Taboo...)
+}
+
+// rerollsLeft menghitung sisa jatah ganti kata seorang pemain di permainan ini.
+func (s *GameState) rerollsLeft(playerID int64) int {
+	left := s.Settings.MaxRerolls - s.Rerolls[playerID]
+	if left < 0 {
+		return 0
+	}
+	return left
+}
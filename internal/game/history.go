@@ -0,0 +1,122 @@
+package game
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// maxHistoryPerChat membatasi jumlah kata yang diingat per chat di memori.
+const maxHistoryPerChat = 500
+
+// WordHistory mengingat kapan setiap kata terakhir dipakai di tiap chat,
+// supaya kata yang sama tidak cepat muncul lagi.
+type WordHistory struct {
+	mu    sync.Mutex
+	chats map[int64]map[string]time.Time
+}
+
+func NewWordHistory() *WordHistory {
+	return &WordHistory{chats: make(map[int64]map[string]time.Time)}
+}
+
+// Loaded memberi tahu apakah riwayat chat ini sudah ada di memori.
+func (h *WordHistory) Loaded(chatID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.chats[chatID]
+	return ok
+}
+
+// Load mengisi riwayat chat dari database tanpa menimpa pemakaian yang lebih baru.
+func (h *WordHistory) Load(chatID int64, usages []db.WordUsage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	used := h.chat(chatID)
+	for _, u := range usages {
+		key := strings.ToUpper(u.Word)
+		if u.UsedAt.After(used[key]) {
+			used[key] = u.UsedAt
+		}
+	}
+}
+
+// Record mencatat pemakaian sebuah kata di sebuah chat.
+func (h *WordHistory) Record(chatID int64, word string, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	used := h.chat(chatID)
+	used[strings.ToUpper(word)] = at
+
+	if len(used) > maxHistoryPerChat {
+		var oldest string
+		for w, t := range used {
+			if oldest == "" || t.Before(used[oldest]) {
+				oldest = w
+			}
+		}
+		delete(used, oldest)
+	}
+}
+
+// Choose memilih kata dari candidates yang tidak termasuk `window` kata terakhir
+// yang dipakai di chat ini. Jika semua kandidat sudah terpakai, kata yang paling
+// lama tidak dipakai yang dipilih.
+func (h *WordHistory) Choose(chatID int64, candidates []db.Word, window int) (db.Word, bool) {
+	if len(candidates) == 0 {
+		return db.Word{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	used := h.chat(chatID)
+
+	recent := make(map[string]bool)
+	if window > 0 {
+		words := make([]string, 0, len(used))
+		for w := range used {
+			words = append(words, w)
+		}
+		sort.Slice(words, func(i, j int) bool {
+			return used[words[i]].After(used[words[j]])
+		})
+		if len(words) > window {
+			words = words[:window]
+		}
+		for _, w := range words {
+			recent[w] = true
+		}
+	}
+
+	var fresh []db.Word
+	for _, c := range candidates {
+		if !recent[strings.ToUpper(c.Word)] {
+			fresh = append(fresh, c)
+		}
+	}
+	if len(fresh) > 0 {
+		return fresh[rand.Intn(len(fresh))], true
+	}
+
+	// Semua kata sudah dipakai baru-baru ini: ambil yang paling lama.
+	oldest := candidates[0]
+	for _, c := range candidates[1:] {
+		if used[strings.ToUpper(c.Word)].Before(used[strings.ToUpper(oldest.Word)]) {
+			oldest = c
+		}
+	}
+	return oldest, true
+}
+
+func (h *WordHistory) chat(chatID int64) map[string]time.Time {
+	used, ok := h.chats[chatID]
+	if !ok {
+		used = make(map[string]time.Time)
+		h.chats[chatID] = used
+	}
+	return used
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+func testWords(list ...string) []db.Word {
+	result := make([]db.Word, len(list))
+	for i, w := range list {
+		result[i] = db.Word{Word: w}
+	}
+	return result
+}
+
+func TestWordHistoryNoRepeatWithinWindow(t *testing.T) {
+	h := NewWordHistory()
+	candidates := testWords("kucing", "anjing", "kelinci", "burung", "ikan")
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Sama seperti PickWord: pilih, lalu catat pemakaiannya.
+	var picked []string
+	for i := 0; i < 100; i++ {
+		word, ok := h.Choose(-100, candidates, 3)
+		if !ok {
+			t.Fatal("no word chosen")
+		}
+		for j := len(picked) - 1; j >= 0 && j >= len(picked)-3; j-- {
+			if picked[j] == word.Word {
+				t.Fatalf("pick %d: %q repeated within the last 3 words %v", i, word.Word, picked[len(picked)-3:])
+			}
+		}
+		picked = append(picked, word.Word)
+		at = at.Add(time.Minute)
+		h.Record(-100, word.Word, at)
+	}
+}
+
+func TestWordHistoryFallsBackToLeastRecentlyUsed(t *testing.T) {
+	h := NewWordHistory()
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.Load(-100, []db.WordUsage{
+		{ChatID: -100, Word: "KELINCI", UsedAt: at.Add(2 * time.Minute)},
+		{ChatID: -100, Word: "kucing", UsedAt: at},
+	})
+	h.Record(-100, "anjing", at.Add(time.Minute))
+
+	// Jendelanya lebih besar dari katalog, jadi semua kata dianggap baru dipakai.
+	candidates := testWords("Kelinci", "Anjing", "Kucing")
+	for _, want := range []string{"Kucing", "Anjing", "Kelinci", "Kucing"} {
+		word, ok := h.Choose(-100, candidates, 10)
+		if !ok || word.Word != want {
+			t.Fatalf("chose %q, want %q", word.Word, want)
+		}
+		at = at.Add(time.Hour)
+		h.Record(-100, word.Word, at)
+	}
+
+	if _, ok := h.Choose(-100, nil, 10); ok {
+		t.Error("chose a word from an empty catalog")
+	}
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"strings"
+	"unicode"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// MatchResult adalah hasil perbandingan tebakan dengan kata rahasia.
+type MatchResult int
+
+const (
+	MatchNone MatchResult = iota
+	// MatchClose berarti tebakan hanya meleset satu-dua huruf.
+	MatchClose
+	MatchExact
+)
+
+// diacritics memetakan huruf beraksen ke huruf dasarnya.
+var diacritics = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i',
+	'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'š': 's',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ž': 'z',
+}
+
+// Normalize menyeragamkan teks untuk dibandingkan: huruf kecil, tanpa aksen,
+// tanpa spasi maupun tanda baca. "Rumah-Sakit!" dan "rumahsakit" menjadi sama.
+func Normalize(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if base, ok := diacritics[r]; ok {
+			r = base
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// MatchGuess membandingkan tebakan dengan kata rahasia beserta alias-aliasnya.
+func MatchGuess(guess string, word db.Word) MatchResult {
+	normalized := Normalize(guess)
+	if normalized == "" {
+		return MatchNone
+	}
+
+	answers := append([]string{word.Word}, word.Aliases...)
+	result := MatchNone
+	for _, answer := range answers {
+		target := Normalize(answer)
+		if target == "" {
+			continue
+		}
+		if normalized == target {
+			return MatchExact
+		}
+		if editDistance(normalized, target) <= closeDistance(target) {
+			result = MatchClose
+		}
+	}
+	return result
+}
+
+// closeDistance menentukan berapa huruf boleh meleset agar tebakan dianggap "hampir".
+// Kata pendek terlalu mudah ditebak jika diberi kelonggaran.
+func closeDistance(word string) int {
+	n := len([]rune(word))
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 5:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance menghitung jarak Levenshtein antara dua kata.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"testing"
+
+	"detektif-kata-bot/internal/db"
+)
+
+func TestMatchGuess(t *testing.T) {
+	rumahSakit := db.Word{Word: "RUMAH SAKIT"}
+	tests := []struct {
+		name  string
+		guess string
+		word  db.Word
+		want  MatchResult
+	}{
+		// Spasi, huruf besar, tanda baca dan aksen tidak berpengaruh
+		{"without space", "rumahsakit", rumahSakit, MatchExact},
+		{"double space", "rumah  sakit", rumahSakit, MatchExact},
+		{"mixed case", "Rumah Sakit", rumahSakit, MatchExact},
+		{"punctuation", "rumah-sakit!", rumahSakit, MatchExact},
+		{"diacritics", "kafé", db.Word{Word: "kafe"}, MatchExact},
+		{"alias", "kitty", db.Word{Word: "kucing", Aliases: []string{"Kitty"}}, MatchExact},
+
+		// Salah ketik satu huruf hanya "hampir", bukan menang
+		{"one-letter typo", "kucinh", db.Word{Word: "kucing"}, MatchClose},
+		{"missing letter", "rumah sakt", rumahSakit, MatchClose},
+		{"typo in five letters", "bukj", db.Word{Word: "buku"}, MatchClose},
+		{"too far off", "kambing", db.Word{Word: "kucing"}, MatchNone},
+
+		// Kata pendek harus tepat
+		{"short word typo", "apu", db.Word{Word: "api"}, MatchNone},
+		{"short word exact", "API", db.Word{Word: "api"}, MatchExact},
+
+		// Singkatan bukan jawaban kecuali terdaftar sebagai alias
+		{"abbreviation", "RS", rumahSakit, MatchNone},
+		{"abbreviation alias", "RS", db.Word{Word: "rumah sakit", Aliases: []string{"rs"}}, MatchExact},
+		{"only punctuation", "?!", rumahSakit, MatchNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchGuess(tt.guess, tt.word); got != tt.want {
+				t.Errorf("MatchGuess(%q, %q) = %v, want %v", tt.guess, tt.word.Word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Rumah-Sakit!", "rumahsakit"},
+		{"  rumah \t sakit ", "rumahsakit"},
+		{"Crème Brûlée", "cremebrulee"},
+		{"7 Keajaiban", "7keajaiban"},
+		{"...", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"kucing", "kucing", 0},
+		{"kucing", "kucinh", 1},
+		{"kucing", "kucin", 1},
+		{"kucing", "kuching", 1},
+		{"", "abc", 3},
+		{"kafé", "kafe", 1},
+		{"kucing", "anjing", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"math"
+	"time"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// Settings berisi aturan waktu dan skor yang dipakai engine selama satu permainan.
+type Settings struct {
+	ClueReminder   time.Duration
+	ClueTimeout    time.Duration
+	MaxMissedTurns int
+	GuessDuration  time.Duration
+	WarningBefore  time.Duration
+	PointTiers     []int
+	// NoRepeatWindow adalah jumlah kata terakhir di grup yang tidak boleh muncul lagi.
+	NoRepeatWindow int
+	// MaxRerolls adalah jatah ganti kata untuk setiap pemain dalam satu permainan.
+	MaxRerolls int
+	// MaxPlayers membatasi jumlah pemain; 0 berarti tanpa batas.
+	MaxPlayers int
+	// AutoStart memulai permainan otomatis setelah pemain cukup; 0 berarti mati.
+	AutoStart   time.Duration
+	LobbyExpiry time.Duration
+	// MinPlayersForWin adalah jumlah pemain minimal agar kemenangan dihitung ke statistik.
+	MinPlayersForWin int
+}
+
+// SettingsFromChat mengubah pengaturan grup dari database menjadi Settings engine.
+func SettingsFromChat(cs *db.ChatSettings) Settings {
+	tiers := make([]int, len(cs.PointTiers))
+	copy(tiers, cs.PointTiers)
+	return Settings{
+		ClueReminder:     time.Duration(cs.ClueReminderSeconds) * time.Second,
+		ClueTimeout:      time.Duration(cs.ClueTimeoutSeconds) * time.Second,
+		MaxMissedTurns:   cs.MaxMissedTurns,
+		GuessDuration:    time.Duration(cs.GuessSeconds) * time.Second,
+		WarningBefore:    time.Duration(cs.WarningSeconds) * time.Second,
+		PointTiers:       tiers,
+		NoRepeatWindow:   cs.NoRepeatWindow,
+		MaxRerolls:       cs.MaxRerolls,
+		MaxPlayers:       cs.MaxPlayers,
+		AutoStart:        time.Duration(cs.AutoStartSeconds) * time.Second,
+		LobbyExpiry:      time.Duration(cs.LobbyExpirySeconds) * time.Second,
+		MinPlayersForWin: cs.MinPlayersForWin,
+	}
+}
+
+// DefaultSettings mengembalikan Settings bawaan.
+func DefaultSettings() Settings {
+	return SettingsFromChat(db.DefaultChatSettings(0))
+}
+
+// TabooPoints menghitung poin pada mode tabu. Petunjuk lebih sulit dibuat,
+// jadi penebak mendapat tambahan setengah poin dan Pemberi Petunjuk ikut
+// mendapat setengah poin dasar.
+func (s Settings) TabooPoints(seconds float64) (guesser, clueGiver int) {
+	base := s.GuessPoints(seconds)
+	return base + base/2, base / 2
+}
+
+// GuessPoints menghitung poin penebak berdasarkan kecepatan menebak. Waktu
+// menebak dibagi rata sesuai jumlah tingkatan poin; makin cepat, makin besar.
+func (s Settings) GuessPoints(seconds float64) int {
+	if len(s.PointTiers) == 0 {
+		return 0
+	}
+	slot := s.GuessDuration.Seconds() / float64(len(s.PointTiers))
+	tier := 0
+	if slot > 0 {
+		tier = int(math.Ceil(seconds/slot)) - 1
+	}
+	if tier < 0 {
+		tier = 0
+	}
+	if tier >= len(s.PointTiers) {
+		tier = len(s.PointTiers) - 1
+	}
+	return s.PointTiers[tier]
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"encoding/json"
+
+	"detektif-kata-bot/internal/db"
+)
+
+// Snapshot mengubah State menjadi JSON untuk disimpan di database.
+func (s *GameState) Snapshot() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// RestoreGame membangun kembali GameState dari hasil Snapshot.
+func RestoreGame(data []byte) (*GameState, error) {
+	var s GameState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Players == nil {
+		s.Players = make(map[int64]*db.Player)
+	}
+	if s.LeftPlayers == nil {
+		s.LeftPlayers = make(map[int64]*db.Player)
+	}
+	if s.SessionScores == nil {
+		s.SessionScores = make(map[int64]int)
+	}
+	if s.Deadlines == nil {
+		s.Deadlines = make(map[TimerKind]Deadline)
+	}
+	if s.Settings.GuessDuration == 0 {
+		s.Settings = DefaultSettings()
+	}
+	if s.Settings.MinPlayersForWin == 0 {
+		s.Settings.MinPlayersForWin = DefaultSettings().MinPlayersForWin
+	}
+	if s.MissedTurns == nil {
+		s.MissedTurns = make(map[int64]int)
+	}
+	if s.Rerolls == nil {
+		s.Rerolls = make(map[int64]int)
+	}
+	if s.WrongGuesses == nil {
+		s.WrongGuesses = make([]string, 0)
+	}
+
+	// JSON menyalin setiap pointer pemain, jadi sambungkan lagi ke entri di Players.
+	s.Host = s.relink(s.Host)
+	s.ClueGiver = s.relink(s.ClueGiver)
+	for i, p := range s.TurnOrder {
+		s.TurnOrder[i] = s.relink(p)
+	}
+	return &s, nil
+}
+
+func (s *GameState) relink(p *db.Player) *db.Player {
+	if p == nil {
+		return nil
+	}
+	if existing, ok := s.Players[p.TelegramUserID]; ok {
+		return existing
+	}
+	if existing, ok := s.LeftPlayers[p.TelegramUserID]; ok {
+		return existing
+	}
+	return p
+}
+
+// Snapshot mengubah permainan solo menjadi JSON untuk disimpan di database.
+func (s *SoloGameState) Snapshot() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// RestoreSoloGame membangun kembali SoloGameState dari hasil Snapshot.
+func RestoreSoloGame(data []byte) (*SoloGameState, error) {
+	var s SoloGameState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
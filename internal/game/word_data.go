@@ -1,8 +1,9 @@
 package game
 
 type WordData struct {
-	Word  string
-	Hints []string
+	Word    string
+	Aliases []string
+	Hints   []string
 }
 
 var SoloWordList = []WordData{
@@ -16,4 +16,23 @@ var WordList = []string{
 	"LIBURAN",
 	"OLAHRAGA",
 	"BUKU",
+}
+
+// TabooList berisi kata terlarang cadangan untuk mode tabu.
+var TabooList = map[string][]string{
+	"SEKOLAH":     {"GURU", "MURID", "BELAJAR"},
+	"MEMANCING":   {"IKAN", "KAIL", "JORAN"},
+	"KEMERDEKAAN": {"MERDEKA", "AGUSTUS", "PROKLAMASI"},
+	"RESTORAN":    {"MAKAN", "MENU", "PELAYAN"},
+	"GUNUNG":      {"TINGGI", "PUNCAK", "DAKI"},
+	"PANTAI":      {"LAUT", "PASIR", "OMBAK"},
+	"BIOSKOP":     {"FILM", "LAYAR", "TIKET"},
+	"RUMAH SAKIT": {"DOKTER", "SAKIT", "PERAWAT"},
+	"PASAR":       {"JUAL", "BELI", "PEDAGANG"},
+	"KOMPUTER":    {"LAPTOP", "LAYAR", "KEYBOARD"},
+	"HUJAN":       {"AIR", "PAYUNG", "BASAH"},
+	"MUSIK":       {"LAGU", "NADA", "PENYANYI"},
+	"LIBURAN":     {"JALAN", "CUTI", "WISATA"},
+	"OLAHRAGA":    {"SEHAT", "LARI", "BOLA"},
+	"BUKU":        {"BACA", "HALAMAN", "PERPUSTAKAAN"},
 }
\ No newline at end of file
@@ -0,0 +1,126 @@
+// Package rating menghitung rating kemampuan pemain dengan sistem Glicko.
+// Setiap permainan grup dianggap sebagai serangkaian pertandingan satu lawan
+// satu: pemain menang melawan semua yang peringkatnya di bawahnya, seri
+// dengan yang peringkatnya sama, dan kalah dari yang di atasnya.
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultValue dan DefaultDeviation adalah rating awal pemain baru.
+	DefaultValue     = 1500.0
+	DefaultDeviation = 350.0
+	// MinDeviation menjaga rating pemain lama tetap bisa bergerak.
+	MinDeviation = 50.0
+	// decayPerDay membuat deviasi naik dari MinDeviation kembali ke
+	// DefaultDeviation setelah sekitar 180 hari tidak bermain.
+	decayPerDay = 25.8
+)
+
+var q = math.Ln10 / 400
+
+// Rating adalah rating satu pemain beserta ketidakpastiannya (deviasi).
+// Makin kecil deviasinya, makin yakin kita bahwa nilainya akurat.
+type Rating struct {
+	Value     float64
+	Deviation float64
+	// RatedAt adalah waktu permainan berperingkat terakhir, nil jika belum pernah.
+	RatedAt *time.Time
+}
+
+// Result adalah peringkat akhir satu pemain di sebuah permainan.
+type Result struct {
+	PlayerID  int64
+	Placement int
+	Rating    Rating
+}
+
+// New mengembalikan rating awal pemain baru.
+func New() Rating {
+	return Rating{Value: DefaultValue, Deviation: DefaultDeviation}
+}
+
+// Decayed menaikkan deviasi sesuai lama pemain tidak bermain, jadi rating
+// pemain yang lama absen bergerak lebih cepat saat ia kembali.
+func (r Rating) Decayed(now time.Time) Rating {
+	if r.Value == 0 || r.Deviation == 0 {
+		r.Value, r.Deviation = DefaultValue, DefaultDeviation
+	}
+	if r.RatedAt == nil {
+		return r
+	}
+	days := now.Sub(*r.RatedAt).Hours() / 24
+	if days <= 0 {
+		return r
+	}
+	r.Deviation = math.Min(math.Sqrt(r.Deviation*r.Deviation+decayPerDay*decayPerDay*days), DefaultDeviation)
+	return r
+}
+
+// Conservative adalah batas bawah rating (nilai dikurangi dua kali deviasi),
+// dipakai untuk papan peringkat supaya pemain yang baru main sekali tidak
+// langsung berada di puncak.
+func (r Rating) Conservative() float64 {
+	return r.Value - 2*r.Deviation
+}
+
+// Update menghitung rating baru semua pemain dari hasil satu permainan.
+// Semua pemain dinilai terhadap rating lawan sebelum permainan dimulai.
+func Update(results []Result, now time.Time) map[int64]Rating {
+	before := make([]Rating, len(results))
+	for i, res := range results {
+		before[i] = res.Rating.Decayed(now)
+	}
+
+	updated := make(map[int64]Rating, len(results))
+	for i, res := range results {
+		r := before[i]
+		var sumVariance, sumDelta float64
+		for j, opp := range results {
+			if i == j {
+				continue
+			}
+			g := gFactor(before[j].Deviation)
+			e := expected(r.Value, before[j].Value, g)
+			sumVariance += g * g * e * (1 - e)
+			sumDelta += g * (score(res.Placement, opp.Placement) - e)
+		}
+		if sumVariance == 0 {
+			updated[res.PlayerID] = r
+			continue
+		}
+
+		dSquared := 1 / (q * q * sumVariance)
+		precision := 1/(r.Deviation*r.Deviation) + 1/dSquared
+		at := now
+		updated[res.PlayerID] = Rating{
+			Value:     r.Value + q/precision*sumDelta,
+			Deviation: math.Max(math.Sqrt(1/precision), MinDeviation),
+			RatedAt:   &at,
+		}
+	}
+	return updated
+}
+
+func gFactor(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*q*q*deviation*deviation/(math.Pi*math.Pi))
+}
+
+func expected(value, opponent, g float64) float64 {
+	return 1 / (1 + math.Pow(10, -g*(value-opponent)/400))
+}
+
+// score adalah hasil pertandingan melawan satu lawan: 1 menang, 0.5 seri, 0 kalah.
+func score(placement, opponent int) float64 {
+	switch {
+	case placement < opponent:
+		return 1
+	case placement == opponent:
+		return 0.5
+	default:
+		return 0
+	}
+}
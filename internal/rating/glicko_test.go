@@ -0,0 +1,162 @@
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestExpected(t *testing.T) {
+	tests := []struct {
+		name               string
+		value, opponent, g float64
+		want               float64
+	}{
+		{name: "equal ratings", value: 1500, opponent: 1500, g: 1, want: 0.5},
+		{name: "400 points stronger", value: 1900, opponent: 1500, g: 1, want: 10.0 / 11},
+		{name: "400 points weaker", value: 1500, opponent: 1900, g: 1, want: 1.0 / 11},
+		// Lawan yang ratingnya tidak pasti membuat hasilnya lebih dekat ke 0.5.
+		{name: "uncertain opponent", value: 1900, opponent: 1500, g: gFactor(DefaultDeviation), want: 0.824},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expected(tt.value, tt.opponent, tt.g); !near(got, tt.want, 0.001) {
+				t.Errorf("expected(%v, %v, %.3f) = %.4f, want %.4f", tt.value, tt.opponent, tt.g, got, tt.want)
+			}
+		})
+	}
+
+	if g := gFactor(MinDeviation); g >= 1 || g <= gFactor(DefaultDeviation) {
+		t.Errorf("gFactor(%v) = %v, want below 1 and above gFactor(%v)", MinDeviation, g, DefaultDeviation)
+	}
+}
+
+func TestDecayed(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+
+	tests := []struct {
+		name          string
+		rating        Rating
+		wantValue     float64
+		wantDeviation float64
+	}{
+		{name: "never rated", rating: Rating{Value: 1600, Deviation: 200}, wantValue: 1600, wantDeviation: 200},
+		{name: "zero rating is a new player", rating: Rating{}, wantValue: DefaultValue, wantDeviation: DefaultDeviation},
+		{name: "played today", rating: Rating{Value: 1700, Deviation: MinDeviation, RatedAt: &now}, wantValue: 1700, wantDeviation: MinDeviation},
+		{name: "30 days inactive", rating: Rating{Value: 1700, Deviation: MinDeviation, RatedAt: daysAgo(30)}, wantValue: 1700, wantDeviation: math.Sqrt(MinDeviation*MinDeviation + decayPerDay*decayPerDay*30)},
+		{name: "capped after a long break", rating: Rating{Value: 1700, Deviation: MinDeviation, RatedAt: daysAgo(400)}, wantValue: 1700, wantDeviation: DefaultDeviation},
+		{name: "rated in the future", rating: Rating{Value: 1700, Deviation: 80, RatedAt: daysAgo(-1)}, wantValue: 1700, wantDeviation: 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rating.Decayed(now)
+			if got.Value != tt.wantValue || !near(got.Deviation, tt.wantDeviation, 1e-9) {
+				t.Errorf("Decayed = %.1f ± %.2f, want %.1f ± %.2f", got.Value, got.Deviation, tt.wantValue, tt.wantDeviation)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	fresh := New()
+
+	t.Run("tie between equals keeps the value", func(t *testing.T) {
+		got := Update([]Result{
+			{PlayerID: 1, Placement: 1, Rating: fresh},
+			{PlayerID: 2, Placement: 1, Rating: fresh},
+		}, now)
+		for id, r := range got {
+			if !near(r.Value, DefaultValue, 1e-9) {
+				t.Errorf("player %d value = %v, want %v", id, r.Value, DefaultValue)
+			}
+			if r.Deviation >= DefaultDeviation {
+				t.Errorf("player %d deviation = %v, want below %v", id, r.Deviation, DefaultDeviation)
+			}
+			if r.RatedAt == nil || !r.RatedAt.Equal(now) {
+				t.Errorf("player %d rated at %v, want %v", id, r.RatedAt, now)
+			}
+		}
+	})
+
+	t.Run("winner gains what the loser loses", func(t *testing.T) {
+		got := Update([]Result{
+			{PlayerID: 1, Placement: 1, Rating: fresh},
+			{PlayerID: 2, Placement: 2, Rating: fresh},
+		}, now)
+		gain, loss := got[1].Value-DefaultValue, DefaultValue-got[2].Value
+		if gain <= 0 || !near(gain, loss, 1e-9) {
+			t.Errorf("gain %.2f, loss %.2f, want equal and positive", gain, loss)
+		}
+	})
+
+	t.Run("tied winners end up level", func(t *testing.T) {
+		got := Update([]Result{
+			{PlayerID: 1, Placement: 1, Rating: fresh},
+			{PlayerID: 2, Placement: 1, Rating: fresh},
+			{PlayerID: 3, Placement: 3, Rating: fresh},
+		}, now)
+		if !near(got[1].Value, got[2].Value, 1e-9) || got[1].Value <= DefaultValue || got[3].Value >= DefaultValue {
+			t.Errorf("ratings = %.1f, %.1f, %.1f, want two equal winners above the loser", got[1].Value, got[2].Value, got[3].Value)
+		}
+	})
+
+	t.Run("upset moves ratings more", func(t *testing.T) {
+		strong := Rating{Value: 1800, Deviation: 100, RatedAt: &now}
+		weak := Rating{Value: 1400, Deviation: 100, RatedAt: &now}
+		expectedWin := Update([]Result{{PlayerID: 1, Placement: 1, Rating: strong}, {PlayerID: 2, Placement: 2, Rating: weak}}, now)
+		upset := Update([]Result{{PlayerID: 1, Placement: 2, Rating: strong}, {PlayerID: 2, Placement: 1, Rating: weak}}, now)
+		if gain, loss := expectedWin[1].Value-strong.Value, strong.Value-upset[1].Value; gain >= loss {
+			t.Errorf("expected win gains %.1f, upset loses %.1f; want the upset to move more", gain, loss)
+		}
+	})
+
+	t.Run("inactive player moves more", func(t *testing.T) {
+		longAgo := now.AddDate(0, 0, -200)
+		active := Rating{Value: 1500, Deviation: MinDeviation, RatedAt: &now}
+		inactive := Rating{Value: 1500, Deviation: MinDeviation, RatedAt: &longAgo}
+		opponent := Result{PlayerID: 9, Placement: 2, Rating: fresh}
+		a := Update([]Result{{PlayerID: 1, Placement: 1, Rating: active}, opponent}, now)[1]
+		b := Update([]Result{{PlayerID: 1, Placement: 1, Rating: inactive}, opponent}, now)[1]
+		if b.Value-1500 <= a.Value-1500 {
+			t.Errorf("inactive gain %.1f, active gain %.1f; want the inactive player to gain more", b.Value-1500, a.Value-1500)
+		}
+	})
+
+	t.Run("alone is unchanged", func(t *testing.T) {
+		got := Update([]Result{{PlayerID: 1, Placement: 1, Rating: fresh}}, now)[1]
+		if got.Value != fresh.Value || got.Deviation != fresh.Deviation || got.RatedAt != nil {
+			t.Errorf("single player rating = %+v, want unchanged", got)
+		}
+	})
+
+	t.Run("deviation never drops below the minimum", func(t *testing.T) {
+		settled := Rating{Value: 1500, Deviation: MinDeviation, RatedAt: &now}
+		var results []Result
+		for id := int64(1); id <= 10; id++ {
+			results = append(results, Result{PlayerID: id, Placement: int(id), Rating: settled})
+		}
+		for id, r := range Update(results, now) {
+			if r.Deviation < MinDeviation {
+				t.Errorf("player %d deviation = %v, want at least %v", id, r.Deviation, MinDeviation)
+			}
+		}
+	})
+}
+
+func TestConservative(t *testing.T) {
+	if got := (Rating{Value: 1800, Deviation: 100}).Conservative(); got != 1600 {
+		t.Errorf("Conservative = %v, want 1600", got)
+	}
+	if New().Conservative() >= (Rating{Value: 1500, Deviation: MinDeviation}).Conservative() {
+		t.Error("a new player should rank below a settled player with the same value")
+	}
+}
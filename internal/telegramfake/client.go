@@ -0,0 +1,262 @@
+// Package telegramfake menyediakan klien Telegram di memori yang mencatat
+// semua pesan keluar, suntingan dan penghapusan, supaya alur bot bisa
+// dijalankan tanpa jaringan.
+package telegramfake
+
+import (
+	"strings"
+	"sync"
+
+	"detektif-kata-bot/internal/bot"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Message adalah pesan yang dikirim bot.
+type Message struct {
+	ChatID      int64
+	MessageID   int
+	Text        string
+	ReplyMarkup interface{}
+}
+
+// Edit adalah suntingan teks atau tombol pada pesan yang sudah ada.
+type Edit struct {
+	ChatID      int64
+	MessageID   int
+	Text        string
+	ReplyMarkup *tgbotapi.InlineKeyboardMarkup
+}
+
+// Deletion adalah pesan yang dihapus bot.
+type Deletion struct {
+	ChatID    int64
+	MessageID int
+}
+
+// Client memenuhi bot.TelegramClient. Aman dipakai dari banyak goroutine.
+type Client struct {
+	mu        sync.Mutex
+	nextID    int
+	sent      []Message
+	edits     []Edit
+	deletions []Deletion
+	callbacks []tgbotapi.CallbackConfig
+	members   map[[2]int64]string
+	failures  map[int64]error
+	updates   chan tgbotapi.Update
+}
+
+var _ bot.TelegramClient = (*Client)(nil)
+
+func New() *Client {
+	return &Client{
+		members:  make(map[[2]int64]string),
+		failures: make(map[int64]error),
+		updates:  make(chan tgbotapi.Update, 100),
+	}
+}
+
+// Send mencatat pesan baru dan memberinya ID, atau mencatat suntingan,
+// penghapusan dan jawaban callback sesuai jenisnya.
+func (c *Client) Send(ch tgbotapi.Chattable) (tgbotapi.Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch m := ch.(type) {
+	case tgbotapi.MessageConfig:
+		return c.record(m.ChatID, m.Text, m.ReplyMarkup)
+	case tgbotapi.PhotoConfig:
+		return c.record(m.ChatID, m.Caption, m.ReplyMarkup)
+	}
+	return tgbotapi.Message{}, c.apply(ch)
+}
+
+// Request berperilaku seperti Send, tetapi mengembalikan APIResponse.
+func (c *Client) Request(ch tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	switch ch.(type) {
+	case tgbotapi.MessageConfig, tgbotapi.PhotoConfig:
+		if _, err := c.Send(ch); err != nil {
+			return nil, err
+		}
+		return &tgbotapi.APIResponse{Ok: true}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.apply(ch); err != nil {
+		return nil, err
+	}
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+// GetChatMember mengembalikan status yang diatur lewat SetMember, atau "member".
+func (c *Client) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.members[[2]int64{config.ChatID, config.UserID}]
+	if !ok {
+		status = "member"
+	}
+	return tgbotapi.ChatMember{User: &tgbotapi.User{ID: config.UserID}, Status: status}, nil
+}
+
+// GetUpdatesChan mengembalikan kanal yang diisi lewat Push.
+func (c *Client) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return c.updates
+}
+
+// Push memasukkan update seolah-olah datang dari Telegram.
+func (c *Client) Push(update tgbotapi.Update) {
+	c.updates <- update
+}
+
+// Close menutup kanal update sehingga Bot.Start berhenti.
+func (c *Client) Close() {
+	close(c.updates)
+}
+
+// SetMember mengatur status keanggotaan pengguna di sebuah chat, misalnya "administrator".
+func (c *Client) SetMember(chatID, userID int64, status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.members[[2]int64{chatID, userID}] = status
+}
+
+// FailFor membuat semua pesan ke chat tersebut gagal dengan err, misalnya
+// untuk meniru pengguna yang belum memulai bot. err nil menghapus kegagalan.
+func (c *Client) FailFor(chatID int64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.failures, chatID)
+		return
+	}
+	c.failures[chatID] = err
+}
+
+// Sent mengembalikan salinan semua pesan yang terkirim.
+func (c *Client) Sent() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.sent...)
+}
+
+// SentTo mengembalikan pesan yang terkirim ke satu chat.
+func (c *Client) SentTo(chatID int64) []Message {
+	var result []Message
+	for _, m := range c.Sent() {
+		if m.ChatID == chatID {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// LastSentTo mengembalikan pesan terakhir ke sebuah chat.
+func (c *Client) LastSentTo(chatID int64) (Message, bool) {
+	msgs := c.SentTo(chatID)
+	if len(msgs) == 0 {
+		return Message{}, false
+	}
+	return msgs[len(msgs)-1], true
+}
+
+// FindSent mencari pesan pertama ke chat tersebut yang mengandung teks tertentu.
+func (c *Client) FindSent(chatID int64, contains string) (Message, bool) {
+	for _, m := range c.SentTo(chatID) {
+		if strings.Contains(m.Text, contains) {
+			return m, true
+		}
+	}
+	return Message{}, false
+}
+
+// Edits mengembalikan salinan semua suntingan pesan.
+func (c *Client) Edits() []Edit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Edit(nil), c.edits...)
+}
+
+// Deletions mengembalikan salinan semua pesan yang dihapus.
+func (c *Client) Deletions() []Deletion {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Deletion(nil), c.deletions...)
+}
+
+// Callbacks mengembalikan semua jawaban callback query.
+func (c *Client) Callbacks() []tgbotapi.CallbackConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]tgbotapi.CallbackConfig(nil), c.callbacks...)
+}
+
+// Reset menghapus semua catatan tanpa mengubah status anggota dan kegagalan.
+func (c *Client) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent, c.edits, c.deletions, c.callbacks = nil, nil, nil, nil
+}
+
+func (c *Client) record(chatID int64, text string, markup interface{}) (tgbotapi.Message, error) {
+	if err := c.failures[chatID]; err != nil {
+		return tgbotapi.Message{}, err
+	}
+	c.nextID++
+	c.sent = append(c.sent, Message{ChatID: chatID, MessageID: c.nextID, Text: text, ReplyMarkup: markup})
+	return tgbotapi.Message{MessageID: c.nextID, Chat: &tgbotapi.Chat{ID: chatID}, Text: text}, nil
+}
+
+func (c *Client) apply(ch tgbotapi.Chattable) error {
+	switch m := ch.(type) {
+	case tgbotapi.EditMessageTextConfig:
+		if err := c.failures[m.ChatID]; err != nil {
+			return err
+		}
+		c.edits = append(c.edits, Edit{ChatID: m.ChatID, MessageID: m.MessageID, Text: m.Text, ReplyMarkup: m.ReplyMarkup})
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		if err := c.failures[m.ChatID]; err != nil {
+			return err
+		}
+		c.edits = append(c.edits, Edit{ChatID: m.ChatID, MessageID: m.MessageID, ReplyMarkup: m.ReplyMarkup})
+	case tgbotapi.DeleteMessageConfig:
+		c.deletions = append(c.deletions, Deletion{ChatID: m.ChatID, MessageID: m.MessageID})
+	case tgbotapi.CallbackConfig:
+		c.callbacks = append(c.callbacks, m)
+	}
+	return nil
+}
+
+// TextUpdate membuat update pesan teks. Teks yang diawali "/" ditandai
+// sebagai perintah, sama seperti yang dilakukan Telegram.
+func TextUpdate(chat tgbotapi.Chat, from tgbotapi.User, messageID int, text string) tgbotapi.Update {
+	msg := &tgbotapi.Message{MessageID: messageID, From: &from, Chat: &chat, Text: text}
+	if strings.HasPrefix(text, "/") {
+		length := len(text)
+		if i := strings.IndexByte(text, ' '); i >= 0 {
+			length = i
+		}
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: length}}
+	}
+	return tgbotapi.Update{Message: msg}
+}
+
+// ReplyUpdate membuat update pesan teks yang membalas pesan lain.
+func ReplyUpdate(chat tgbotapi.Chat, from tgbotapi.User, messageID int, text string, replyTo int) tgbotapi.Update {
+	update := TextUpdate(chat, from, messageID, text)
+	update.Message.ReplyToMessage = &tgbotapi.Message{MessageID: replyTo, Chat: &chat}
+	return update
+}
+
+// CallbackUpdate membuat update penekanan tombol inline pada sebuah pesan.
+func CallbackUpdate(chat tgbotapi.Chat, from tgbotapi.User, messageID int, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      data,
+		From:    &from,
+		Message: &tgbotapi.Message{MessageID: messageID, Chat: &chat},
+		Data:    data,
+	}}
+}
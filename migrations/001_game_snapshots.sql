@@ -0,0 +1,8 @@
+-- Snapshot permainan yang sedang berjalan, dipakai untuk melanjutkan game setelah bot restart.
+create table if not exists game_snapshots (
+    kind       text        not null,
+    chat_id    bigint      not null,
+    state      jsonb       not null,
+    updated_at timestamptz not null default now(),
+    primary key (kind, chat_id)
+);
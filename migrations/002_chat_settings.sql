@@ -0,0 +1,11 @@
+-- Pengaturan permainan per grup, diubah lewat perintah /settings.
+create table if not exists chat_settings (
+    chat_id               bigint primary key,
+    guess_seconds         integer not null default 60,
+    warning_seconds       integer not null default 15,
+    clue_reminder_seconds integer not null default 90,
+    default_rounds        integer not null default 10,
+    min_rounds            integer not null default 3,
+    max_rounds            integer not null default 25,
+    point_tiers           integer[] not null default '{20,15,10,5}'
+);
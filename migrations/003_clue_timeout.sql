@@ -0,0 +1,5 @@
+-- Batas waktu keras untuk Pemberi Petunjuk dan jumlah giliran terlewat sebelum pemain dikeluarkan dari giliran.
+alter table chat_settings add column if not exists clue_timeout_seconds integer not null default 180;
+alter table chat_settings add column if not exists max_missed_turns integer not null default 2;
+
+alter table players add column if not exists missed_turns_count integer not null default 0;
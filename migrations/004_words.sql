@@ -0,0 +1,38 @@
+-- Katalog kata rahasia. WordList dan SoloWordList di kode hanya dipakai
+-- sebagai cadangan jika tabel ini kosong atau tidak bisa dibaca.
+create table if not exists words (
+    id         bigserial primary key,
+    word       text not null,
+    category   text not null default 'umum',
+    difficulty integer not null default 1,
+    language   text not null default 'id',
+    aliases    text[] not null default '{}',
+    hints      text[] not null default '{}',
+    unique (language, word)
+);
+
+insert into words (word, category, difficulty, language, aliases, hints) values
+    ('SEKOLAH',     'tempat',   1, 'id', '{}',            '{"GURU","MURID","KELAS"}'),
+    ('RESTORAN',    'tempat',   1, 'id', '{"RUMAH MAKAN"}', '{"MAKANAN","MENU","PELAYAN"}'),
+    ('BIOSKOP',     'tempat',   1, 'id', '{}',            '{"FILM","POPCORN","LAYAR BESAR"}'),
+    ('RUMAH SAKIT', 'tempat',   1, 'id', '{"RS"}',        '{"DOKTER","PERAWAT","PASIEN"}'),
+    ('PASAR',       'tempat',   1, 'id', '{}',            '{"PEDAGANG","TAWAR","SAYUR"}'),
+    ('GUNUNG',      'alam',     1, 'id', '{}',            '{"PENDAKI","PUNCAK","DINGIN"}'),
+    ('PANTAI',      'alam',     1, 'id', '{}',            '{"PASIR","OMBAK","LAUT"}'),
+    ('PULAU',       'alam',     2, 'id', '{}',            '{"LAUT","PANTAI","TERPENCIL"}'),
+    ('HUJAN',       'alam',     1, 'id', '{}',            '{"PAYUNG","AWAN","BASAH"}'),
+    ('KUCING',      'hewan',    1, 'id', '{}',            '{"MEONG","KUMIS","PELIHARAAN"}'),
+    ('GAJAH',       'hewan',    1, 'id', '{}',            '{"BELALAI","BESAR","GADING"}'),
+    ('HARIMAU',     'hewan',    2, 'id', '{"MACAN"}',     '{"BELANG","HUTAN","BUAS"}'),
+    ('JERAPAH',     'hewan',    2, 'id', '{}',            '{"LEHER","TINGGI","AFRIKA"}'),
+    ('KUPU-KUPU',   'hewan',    2, 'id', '{"KUPU KUPU"}', '{"SAYAP","BUNGA","KEPOMPONG"}'),
+    ('LUMBA-LUMBA', 'hewan',    3, 'id', '{"LUMBA LUMBA"}', '{"LAUT","PINTAR","MAMALIA"}'),
+    ('KOMPUTER',    'teknologi', 1, 'id', '{}',           '{"KEYBOARD","LAYAR","INTERNET"}'),
+    ('ASTRONOT',    'teknologi', 3, 'id', '{}',           '{"LUAR ANGKASA","ROKET","BULAN"}'),
+    ('MEMANCING',   'kegiatan', 2, 'id', '{}',            '{"IKAN","KAIL","SABAR"}'),
+    ('MUSIK',       'kegiatan', 1, 'id', '{}',            '{"LAGU","NADA","KONSER"}'),
+    ('LIBURAN',     'kegiatan', 1, 'id', '{}',            '{"KOPER","TIKET","SANTAI"}'),
+    ('OLAHRAGA',    'kegiatan', 1, 'id', '{}',            '{"KERINGAT","SEHAT","BOLA"}'),
+    ('BUKU',        'benda',    1, 'id', '{}',            '{"HALAMAN","MEMBACA","PERPUSTAKAAN"}'),
+    ('KEMERDEKAAN', 'sejarah',  3, 'id', '{}',            '{"SEJARAH","MERAH-PUTIH","AGUSTUS"}')
+on conflict (language, word) do nothing;
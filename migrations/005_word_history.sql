@@ -0,0 +1,9 @@
+-- Riwayat kata per grup, supaya kata yang sama tidak cepat muncul lagi.
+create table if not exists word_history (
+    chat_id bigint not null,
+    word    text not null,
+    used_at timestamptz not null default now(),
+    primary key (chat_id, word)
+);
+
+alter table chat_settings add column if not exists no_repeat_window integer not null default 50;
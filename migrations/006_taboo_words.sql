@@ -0,0 +1,26 @@
+-- Kata terlarang untuk mode tabu.
+alter table words add column if not exists taboo text[] not null default '{}';
+
+update words set taboo = '{"GURU","MURID","BELAJAR"}'        where language = 'id' and word = 'SEKOLAH';
+update words set taboo = '{"MAKAN","MENU","PELAYAN"}'        where language = 'id' and word = 'RESTORAN';
+update words set taboo = '{"FILM","LAYAR","TIKET"}'          where language = 'id' and word = 'BIOSKOP';
+update words set taboo = '{"DOKTER","SAKIT","PERAWAT"}'      where language = 'id' and word = 'RUMAH SAKIT';
+update words set taboo = '{"JUAL","BELI","PEDAGANG"}'        where language = 'id' and word = 'PASAR';
+update words set taboo = '{"TINGGI","PUNCAK","DAKI"}'        where language = 'id' and word = 'GUNUNG';
+update words set taboo = '{"LAUT","PASIR","OMBAK"}'          where language = 'id' and word = 'PANTAI';
+update words set taboo = '{"LAUT","KECIL","TERPENCIL"}'      where language = 'id' and word = 'PULAU';
+update words set taboo = '{"AIR","PAYUNG","BASAH"}'          where language = 'id' and word = 'HUJAN';
+update words set taboo = '{"MEONG","KUMIS","ANJING"}'        where language = 'id' and word = 'KUCING';
+update words set taboo = '{"BELALAI","BESAR","GADING"}'      where language = 'id' and word = 'GAJAH';
+update words set taboo = '{"BELANG","MACAN","SINGA"}'        where language = 'id' and word = 'HARIMAU';
+update words set taboo = '{"LEHER","TINGGI","TUTUL"}'        where language = 'id' and word = 'JERAPAH';
+update words set taboo = '{"SAYAP","BUNGA","KEPOMPONG"}'     where language = 'id' and word = 'KUPU-KUPU';
+update words set taboo = '{"LAUT","IKAN","PINTAR"}'          where language = 'id' and word = 'LUMBA-LUMBA';
+update words set taboo = '{"LAPTOP","LAYAR","KEYBOARD"}'     where language = 'id' and word = 'KOMPUTER';
+update words set taboo = '{"ANGKASA","ROKET","BULAN"}'       where language = 'id' and word = 'ASTRONOT';
+update words set taboo = '{"IKAN","KAIL","JORAN"}'           where language = 'id' and word = 'MEMANCING';
+update words set taboo = '{"LAGU","NADA","PENYANYI"}'        where language = 'id' and word = 'MUSIK';
+update words set taboo = '{"JALAN","CUTI","WISATA"}'         where language = 'id' and word = 'LIBURAN';
+update words set taboo = '{"SEHAT","LARI","BOLA"}'           where language = 'id' and word = 'OLAHRAGA';
+update words set taboo = '{"BACA","HALAMAN","PERPUSTAKAAN"}' where language = 'id' and word = 'BUKU';
+update words set taboo = '{"MERDEKA","AGUSTUS","PROKLAMASI"}' where language = 'id' and word = 'KEMERDEKAAN';
@@ -0,0 +1,13 @@
+-- Jatah ganti kata untuk Pemberi Petunjuk dan catatan kata yang diganti,
+-- supaya kata yang sering dilewati bisa dikenali.
+alter table chat_settings add column if not exists max_rerolls integer not null default 2;
+
+create table if not exists word_rerolls (
+    id          bigserial primary key,
+    chat_id     bigint not null,
+    player_id   bigint not null,
+    word        text not null,
+    rerolled_at timestamptz not null default now()
+);
+
+create index if not exists word_rerolls_word_idx on word_rerolls (word);
@@ -0,0 +1,4 @@
+-- Batas pemain, mulai otomatis dan kedaluwarsa lobi.
+alter table chat_settings add column if not exists max_players integer not null default 10;
+alter table chat_settings add column if not exists auto_start_seconds integer not null default 0;
+alter table chat_settings add column if not exists lobby_expiry_seconds integer not null default 600;
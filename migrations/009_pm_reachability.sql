@@ -0,0 +1,3 @@
+-- Menandai pemain yang sudah memulai chat pribadi dengan bot. Pemain lama
+-- dianggap belum sampai mereka mengirim pesan pribadi lagi.
+alter table players add column if not exists can_receive_pm boolean not null default false;
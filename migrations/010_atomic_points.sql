@@ -0,0 +1,59 @@
+-- Perubahan poin dan statistik dilakukan di server dalam satu UPDATE, supaya
+-- pembaruan yang berjalan bersamaan tidak saling menimpa.
+
+-- Menambah (atau mengurangi) poin dan mengembalikan total barunya.
+create or replace function add_player_points(p_player_id bigint, p_delta integer)
+returns integer
+language sql
+as $$
+    update players
+       set points = points + p_delta
+     where telegram_user_id = p_player_id
+    returning points;
+$$;
+
+-- Mengurangi poin hanya jika saldonya cukup. Mengembalikan saldo baru, atau
+-- null jika pemain tidak ada atau poinnya kurang.
+create or replace function spend_player_points(p_player_id bigint, p_amount integer)
+returns integer
+language sql
+as $$
+    update players
+       set points = points - p_amount
+     where telegram_user_id = p_player_id
+       and points >= p_amount
+    returning points;
+$$;
+
+-- Menambah satu kolom statistik. Nama kolom dibatasi supaya aman dipakai di format().
+create or replace function increment_player_stat(p_player_id bigint, p_field text, p_value integer)
+returns integer
+language plpgsql
+as $$
+declare
+    new_value integer;
+begin
+    if p_field not in ('games_played', 'games_won', 'clue_given_count',
+                       'clue_success_count', 'words_guessed_count', 'missed_turns_count') then
+        raise exception 'unknown player stat %', p_field;
+    end if;
+
+    execute format('update players set %1$I = %1$I + $1 where telegram_user_id = $2 returning %1$I', p_field)
+       into new_value
+      using p_value, p_player_id;
+    return new_value;
+end;
+$$;
+
+-- Menyimpan tebakan tercepat hanya jika lebih cepat dari rekor sebelumnya.
+-- Mengembalikan true jika rekornya berubah, atau null jika tidak.
+create or replace function record_fastest_guess(p_player_id bigint, p_seconds double precision)
+returns boolean
+language sql
+as $$
+    update players
+       set fastest_guess = p_seconds
+     where telegram_user_id = p_player_id
+       and (fastest_guess = -1 or fastest_guess > p_seconds)
+    returning true;
+$$;
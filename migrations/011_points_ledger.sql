@@ -0,0 +1,65 @@
+-- Catatan setiap perubahan saldo poin. Tabel ini hanya ditambah, tidak pernah diubah.
+create table if not exists points_ledger (
+    id         bigserial primary key,
+    player_id  bigint not null references players (telegram_user_id),
+    delta      integer not null,
+    balance    integer not null,
+    reason     text not null,
+    ref        text not null default '',
+    created_at timestamptz not null default now()
+);
+
+create index if not exists points_ledger_player_idx on points_ledger (player_id, id desc);
+
+-- Saldo yang sudah ada sebelum ledger dicatat sebagai saldo awal.
+insert into points_ledger (player_id, delta, balance, reason)
+select telegram_user_id, points, points, 'opening_balance'
+  from players
+ where points <> 0
+   and not exists (select 1 from points_ledger l where l.player_id = players.telegram_user_id);
+
+-- Fungsi poin dari 010 diganti supaya setiap perubahan saldo langsung
+-- dicatat di ledger dalam transaksi yang sama.
+drop function if exists add_player_points(bigint, integer);
+drop function if exists spend_player_points(bigint, integer);
+
+create or replace function add_player_points(p_player_id bigint, p_delta integer, p_reason text, p_ref text)
+returns integer
+language plpgsql
+as $$
+declare
+    new_balance integer;
+begin
+    update players
+       set points = points + p_delta
+     where telegram_user_id = p_player_id
+    returning points into new_balance;
+
+    if found then
+        insert into points_ledger (player_id, delta, balance, reason, ref)
+        values (p_player_id, p_delta, new_balance, p_reason, coalesce(p_ref, ''));
+    end if;
+    return new_balance;
+end;
+$$;
+
+create or replace function spend_player_points(p_player_id bigint, p_amount integer, p_reason text, p_ref text)
+returns integer
+language plpgsql
+as $$
+declare
+    new_balance integer;
+begin
+    update players
+       set points = points - p_amount
+     where telegram_user_id = p_player_id
+       and points >= p_amount
+    returning points into new_balance;
+
+    if found then
+        insert into points_ledger (player_id, delta, balance, reason, ref)
+        values (p_player_id, -p_amount, new_balance, p_reason, coalesce(p_ref, ''));
+    end if;
+    return new_balance;
+end;
+$$;
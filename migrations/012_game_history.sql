@@ -0,0 +1,55 @@
+-- Riwayat lengkap permainan grup: siapa yang main, kata dan petunjuk setiap
+-- ronde, serta semua tebakan. Ditulis sekali saat permainan selesai.
+create table if not exists games (
+    id            text primary key,
+    chat_id       bigint not null,
+    mode          text not null default 'classic',
+    category      text not null default '',
+    rounds_played integer not null,
+    total_rounds  integer not null,
+    end_reason    text not null,
+    winner_id     bigint,
+    started_at    timestamptz not null,
+    ended_at      timestamptz not null default now()
+);
+
+create index if not exists games_chat_idx on games (chat_id, ended_at desc);
+
+create table if not exists game_players (
+    game_id    text not null references games (id) on delete cascade,
+    player_id  bigint not null,
+    first_name text not null default '',
+    points     integer not null default 0,
+    left_early boolean not null default false,
+    primary key (game_id, player_id)
+);
+
+create index if not exists game_players_player_idx on game_players (player_id);
+
+create table if not exists rounds (
+    game_id       text not null references games (id) on delete cascade,
+    round         integer not null,
+    clue_giver_id bigint not null,
+    word          text not null,
+    clue          text not null default '',
+    outcome       text not null,
+    winner_id     bigint,
+    guess_seconds double precision,
+    primary key (game_id, round)
+);
+
+create index if not exists rounds_clue_giver_idx on rounds (clue_giver_id);
+
+create table if not exists guesses (
+    id         bigserial primary key,
+    game_id    text not null references games (id) on delete cascade,
+    round      integer not null,
+    player_id  bigint not null,
+    text       text not null,
+    result     text not null,
+    seconds    double precision not null,
+    guessed_at timestamptz not null default now()
+);
+
+create index if not exists guesses_player_idx on guesses (player_id);
+create index if not exists guesses_game_idx on guesses (game_id, round);
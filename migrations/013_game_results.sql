@@ -0,0 +1,8 @@
+-- Hasil akhir permainan: peringkat setiap pemain, pemenang (termasuk seri),
+-- dan apakah kemenangan dihitung ke statistik games_won.
+alter table chat_settings add column if not exists min_players_for_win integer not null default 3;
+
+alter table games add column if not exists win_counted boolean not null default false;
+
+alter table game_players add column if not exists placement integer not null default 0;
+alter table game_players add column if not exists won boolean not null default false;
@@ -0,0 +1,21 @@
+-- Rating kemampuan pemain (Glicko). Deviasi mengukur ketidakpastian rating
+-- dan naik lagi selama pemain tidak bermain.
+alter table players add column if not exists rating double precision not null default 1500;
+alter table players add column if not exists rating_deviation double precision not null default 350;
+alter table players add column if not exists rated_games integer not null default 0;
+alter table players add column if not exists rated_at timestamptz;
+
+-- Menyimpan rating baru dan menambah jumlah permainan berperingkat dalam satu
+-- UPDATE. Mengembalikan jumlah permainan berperingkat yang baru.
+create or replace function record_player_rating(p_player_id bigint, p_rating double precision, p_deviation double precision)
+returns integer
+language sql
+as $$
+    update players
+       set rating = p_rating,
+           rating_deviation = p_deviation,
+           rated_games = rated_games + 1,
+           rated_at = now()
+     where telegram_user_id = p_player_id
+    returning rated_games;
+$$;
@@ -0,0 +1,37 @@
+-- Rentetan menang untuk lencana win_streak. Hanya permainan yang
+-- kemenangannya dihitung (lihat min_players_for_win) yang memengaruhinya.
+alter table players add column if not exists win_streak integer not null default 0;
+alter table players add column if not exists best_win_streak integer not null default 0;
+
+-- Mencatat hasil satu permainan: menambah games_won jika menang dan
+-- memperbarui rentetan menang. Mengembalikan rentetan yang baru.
+create or replace function record_game_result(p_player_id bigint, p_won boolean)
+returns integer
+language sql
+as $$
+    update players
+       set games_won = games_won + case when p_won then 1 else 0 end,
+           win_streak = case when p_won then win_streak + 1 else 0 end,
+           best_win_streak = greatest(best_win_streak, case when p_won then win_streak + 1 else 0 end)
+     where telegram_user_id = p_player_id
+    returning win_streak;
+$$;
+
+-- Lencana pencapaian untuk kriteria baru. Lencana yang namanya sudah ada dilewati.
+insert into badges (name, description, emoji, type, criteria_type, criteria_value)
+select v.name, v.description, v.emoji, 'achievement', v.criteria_type, v.criteria_value
+  from (values
+    ('Detektif Pemula', 'Menebak 10 kata dengan benar', '🔎', 'words_guessed_count', 10),
+    ('Detektif Ulung', 'Menebak 100 kata dengan benar', '🕵️', 'words_guessed_count', 100),
+    ('Pendatang Setia', 'Bermain 25 permainan grup', '🎲', 'games_played', 25),
+    ('Juara', 'Memenangkan 10 permainan grup', '🏆', 'games_won', 10),
+    ('Pemberi Petunjuk Andal', '50 petunjuk berhasil ditebak', '💡', 'clue_success_count', 50),
+    ('Tak Terbendung', 'Menang 3 permainan berturut-turut', '🔥', 'win_streak', 3),
+    ('Sultan Poin', 'Mengumpulkan 5000 poin', '💰', 'points', 5000),
+    ('Malam Gemilang', 'Mendapat 300 poin dalam satu permainan', '🌟', 'game_points', 300),
+    ('Borong Kata', 'Menebak 5 kata dalam satu permainan', '🧺', 'game_words_guessed', 5),
+    ('Tiga Beruntun', 'Menebak 3 ronde berturut-turut dalam satu permainan', '🎯', 'game_guess_streak', 3),
+    ('Tanpa Bantuan', 'Menang mode solo dengan satu petunjuk saja', '🧠', 'solo_hints', 1),
+    ('Kolektor', 'Memiliki 5 lencana', '🗃️', 'badges_owned', 5)
+  ) as v(name, description, emoji, criteria_type, criteria_value)
+ where not exists (select 1 from badges b where b.name = v.name);
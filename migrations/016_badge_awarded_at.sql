@@ -0,0 +1,4 @@
+-- Waktu lencana didapat, untuk tampilan pencapaian di profil. Lencana yang
+-- didapat sebelum kolom ini ada tanggalnya tidak diketahui (null).
+alter table player_badges add column if not exists awarded_at timestamptz;
+alter table player_badges alter column awarded_at set default now();
@@ -0,0 +1,9 @@
+-- Migrasi 009 menandai semua pemain lama belum bisa menerima PM. Pemain yang
+-- sudah pernah mengirim pesan pribadi ke bot tercatat di tabel chats sebagai
+-- chat "private" dengan chat_id sama dengan ID Telegram-nya.
+update players p
+   set can_receive_pm = true
+  from chats c
+ where c.chat_id = p.telegram_user_id
+   and c.type = 'private'
+   and not p.can_receive_pm;
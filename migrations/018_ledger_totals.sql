@@ -0,0 +1,11 @@
+-- Jumlah entri dan total perubahan ledger seorang pemain, dihitung di server
+-- supaya audit saldo tidak perlu mengambil seluruh riwayat.
+create or replace function points_ledger_totals(p_player_id bigint)
+returns table (entries integer, total integer)
+language sql
+stable
+as $$
+    select count(*)::integer, coalesce(sum(delta), 0)::integer
+      from points_ledger
+     where player_id = p_player_id;
+$$;
@@ -0,0 +1,26 @@
+-- Menyimpan satu permainan beserta pemain, ronde, dan tebakannya dalam satu
+-- transaksi, jadi riwayat tidak pernah tersimpan setengah jalan.
+create or replace function save_game_record(p_game jsonb, p_players jsonb, p_rounds jsonb, p_guesses jsonb)
+returns boolean
+language plpgsql
+as $$
+begin
+    insert into games (id, chat_id, mode, category, rounds_played, total_rounds, end_reason, winner_id, win_counted, started_at, ended_at)
+    select id, chat_id, mode, category, rounds_played, total_rounds, end_reason, winner_id, win_counted, started_at, ended_at
+      from jsonb_populate_record(null::games, p_game);
+
+    insert into game_players (game_id, player_id, first_name, points, left_early, placement, won)
+    select game_id, player_id, first_name, points, left_early, placement, won
+      from jsonb_populate_recordset(null::game_players, coalesce(p_players, '[]'::jsonb));
+
+    insert into rounds (game_id, round, clue_giver_id, word, clue, outcome, winner_id, guess_seconds)
+    select game_id, round, clue_giver_id, word, clue, outcome, winner_id, guess_seconds
+      from jsonb_populate_recordset(null::rounds, coalesce(p_rounds, '[]'::jsonb));
+
+    insert into guesses (game_id, round, player_id, text, result, seconds)
+    select game_id, round, player_id, text, result, seconds
+      from jsonb_populate_recordset(null::guesses, coalesce(p_guesses, '[]'::jsonb));
+
+    return true;
+end;
+$$;
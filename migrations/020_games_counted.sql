@@ -0,0 +1,28 @@
+-- Jumlah permainan yang hasilnya dihitung (bukan permainan yang ditinggalkan
+-- atau yang pemainnya terlalu sedikit). Win rate dihitung dari kolom ini,
+-- bukan dari games_played.
+alter table players add column if not exists games_counted integer not null default 0;
+
+-- Isi dari riwayat permainan. Permainan lama yang belum punya riwayat tidak
+-- diketahui, jadi nilainya minimal sama dengan games_won.
+update players
+   set games_counted = greatest(games_won, (
+       select count(*)
+         from game_players gp
+         join games g on g.id = gp.game_id
+        where gp.player_id = players.telegram_user_id
+          and g.win_counted
+   ));
+
+create or replace function record_game_result(p_player_id bigint, p_won boolean)
+returns integer
+language sql
+as $$
+    update players
+       set games_counted = games_counted + 1,
+           games_won = games_won + case when p_won then 1 else 0 end,
+           win_streak = case when p_won then win_streak + 1 else 0 end,
+           best_win_streak = greatest(best_win_streak, case when p_won then win_streak + 1 else 0 end)
+     where telegram_user_id = p_player_id
+    returning win_streak;
+$$;
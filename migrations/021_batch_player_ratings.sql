@@ -0,0 +1,20 @@
+-- Menyimpan rating baru semua pemain sebuah permainan dalam satu UPDATE, jadi
+-- tidak ada permainan yang ratingnya hanya tersimpan sebagian. p_updates
+-- berisi [{"player_id": ..., "rating": ..., "deviation": ...}]. Mengembalikan
+-- jumlah pemain yang diperbarui.
+create or replace function record_player_ratings(p_updates jsonb)
+returns integer
+language sql
+as $$
+    with updated as (
+        update players p
+           set rating = u.rating,
+               rating_deviation = u.deviation,
+               rated_games = p.rated_games + 1,
+               rated_at = now()
+          from jsonb_to_recordset(p_updates) as u(player_id bigint, rating double precision, deviation double precision)
+         where p.telegram_user_id = u.player_id
+        returning 1
+    )
+    select count(*)::integer from updated;
+$$;
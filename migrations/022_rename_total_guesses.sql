@@ -0,0 +1,6 @@
+-- criteria_type 'total_guesses' hanya pernah menjadi nama lain dari
+-- words_guessed_count. Lencana yang masih memakainya dipindahkan supaya
+-- bot cukup mengenal satu nama.
+update badges
+   set criteria_type = 'words_guessed_count'
+ where criteria_type = 'total_guesses';